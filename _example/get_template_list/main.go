@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -18,11 +19,15 @@ func main() {
 
 	fmt.Printf("CODE CHALLENGE: %s\n", zc.GetCodeChallenge())
 
-	zc.SetAccessToken(client.AccessToken{
-		AccessToken:  os.Getenv("ZALO_ACCESS_TOKEN"),
-		RefreshToken: os.Getenv("ZALO_REFRESH_TOKEN"),
-		ExpiresIn:    90_000,
-	})
+	// ZALO_TOKEN is the JSON token printed by the get_token example
+	var token client.AccessToken
+	if err := json.Unmarshal([]byte(os.Getenv("ZALO_TOKEN")), &token); err != nil {
+		panic(err)
+	}
+	if !token.Valid() {
+		panic("access token has expired, run the get_token example again")
+	}
+	zc.SetAccessToken(token)
 
 	response, err := zc.GetZnsTemplateList(ctx, client.ZnsTplListRequest{
 		Offset: 0,
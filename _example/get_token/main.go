@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -36,4 +37,11 @@ func main() {
 	}
 	zc.SetAccessToken(token)
 	fmt.Printf("After Refresh Token: %+v\n", token)
+
+	// Store this JSON, e.g. in ZALO_TOKEN, to keep the expiry times across restarts
+	tokenJSON, err := json.Marshal(token)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Token JSON: %s\n", tokenJSON)
 }
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/x/znsformat"
 )
 
 func main() {
@@ -18,18 +20,22 @@ func main() {
 
 	fmt.Printf("CODE CHALLENGE: %s\n", zc.GetCodeChallenge())
 
-	zc.SetAccessToken(client.AccessToken{
-		AccessToken:  os.Getenv("ZALO_ACCESS_TOKEN"),
-		RefreshToken: os.Getenv("ZALO_REFRESH_TOKEN"),
-		ExpiresIn:    90_000,
-	})
+	// ZALO_TOKEN is the JSON token printed by the get_token example
+	var token client.AccessToken
+	if err := json.Unmarshal([]byte(os.Getenv("ZALO_TOKEN")), &token); err != nil {
+		panic(err)
+	}
+	if !token.Valid() {
+		panic("access token has expired, run the get_token example again")
+	}
+	zc.SetAccessToken(token)
 
 	response, err := zc.SendZnsMessage(ctx, client.ZnsSendMsgRequest{
 		Phone:      os.Getenv("ZNS_RECEIVER_PHONE"),
 		TemplateID: os.Getenv("ZNS_TEMPLATE_ID"),
 		TemplateData: map[string]string{
-			"cost": "10,000",
-			"note": "10,000 đồng",
+			"cost": znsformat.Number(10000),
+			"note": znsformat.Currency(10000),
 		},
 		TrackingID: "",
 	})
@@ -0,0 +1,180 @@
+// Package catalog keeps a local copy of the ZNS templates of an OA and reports their changes.
+//
+// Templates get approved, rejected, disabled for low quality or edited on the Zalo portal.
+// A Syncer periodically pulls every template with GetZnsTemplateList and GetZnsTemplateDetail,
+// diffs them against the previous snapshot kept in a Store, passes each Change, e.g. a status
+// going from ENABLE to REJECT, to its subscribers and then saves the new snapshot.
+//
+// Changes are delivered at least once: the snapshot is only saved once every subscriber has
+// been called, so a crash or a failed save makes the next sync report the same changes again.
+// Subscribers that must not act twice on a change should be idempotent.
+//
+// The first sync, without previous snapshot, only records the catalog and reports no change.
+package catalog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+// PAGE_SIZE is the number of templates listed per request, the maximum allowed by Zalo.
+const PAGE_SIZE = 100
+
+// Syncer syncs the templates of an OA into a Store. It is safe for concurrent use.
+type Syncer struct {
+	store  Store
+	client *client.ZaloClient
+
+	syncMu sync.Mutex // serializes syncs
+
+	mu          sync.Mutex
+	subscribers map[int]func(ctx context.Context, change Change)
+	nextID      int
+
+	now func() time.Time
+}
+
+// New creates a syncer of the templates of the OA of zc into store.
+func New(store Store, zc *client.ZaloClient) *Syncer {
+	return &Syncer{
+		store:       store,
+		client:      zc,
+		subscribers: make(map[int]func(ctx context.Context, change Change)),
+		now:         time.Now,
+	}
+}
+
+// Subscribe registers a function called with every change found by a sync, in order,
+// before the snapshot is saved. It returns a function removing the subscription.
+func (s *Syncer) Subscribe(fn func(ctx context.Context, change Change)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = fn
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+// Snapshot returns the last synced snapshot, or ErrNoSnapshot before the first sync.
+func (s *Syncer) Snapshot(ctx context.Context) (Snapshot, error) {
+	return s.store.Load(ctx)
+}
+
+// Template returns the last synced detail of a template, and whether it exists.
+func (s *Syncer) Template(ctx context.Context, templateID int) (client.ZnsTplDetailData, bool, error) {
+	snapshot, err := s.store.Load(ctx)
+	if errors.Is(err, ErrNoSnapshot) {
+		return client.ZnsTplDetailData{}, false, nil
+	}
+	if err != nil {
+		return client.ZnsTplDetailData{}, false, err
+	}
+	template, ok := snapshot.Templates[templateID]
+	return template, ok, nil
+}
+
+// Sync pulls every template, notifies the subscribers of the changes and saves the new snapshot,
+// and returns the changes. A Zalo error is returned as a *client.ZnsError; the previous snapshot
+// is then kept, so no change is lost. If saving fails, the changes were delivered but the error
+// is returned and the next sync delivers them again.
+func (s *Syncer) Sync(ctx context.Context) ([]Change, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	previous, err := s.store.Load(ctx)
+	first := errors.Is(err, ErrNoSnapshot)
+	if err != nil && !first {
+		return nil, err
+	}
+
+	templates, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := Snapshot{Templates: templates, SyncedAt: s.now()}
+	if first {
+		return nil, s.store.Save(ctx, snapshot)
+	}
+
+	changes := Diff(previous, snapshot)
+	s.mu.Lock()
+	subscribers := make([]func(ctx context.Context, change Change), 0, len(s.subscribers))
+	for id := 0; id < s.nextID; id++ {
+		if fn, ok := s.subscribers[id]; ok {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	s.mu.Unlock()
+	for _, change := range changes {
+		for _, fn := range subscribers {
+			fn(ctx, change)
+		}
+	}
+	if err := s.store.Save(ctx, snapshot); err != nil {
+		return changes, err
+	}
+	return changes, nil
+}
+
+// Run syncs every interval until ctx is done, and returns the context error.
+// Sync errors are logged and retried on the next tick.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sync(ctx); err != nil && ctx.Err() == nil {
+			s.client.GetLogger().ErrorContext(ctx, "Error syncing template catalog:", slog.Any("err", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetch gets the detail of every template.
+func (s *Syncer) fetch(ctx context.Context) (map[int]client.ZnsTplDetailData, error) {
+	var ids []int
+	for offset := 0; ; offset += PAGE_SIZE {
+		list, err := s.client.GetZnsTemplateList(ctx, client.ZnsTplListRequest{Offset: offset, Limit: PAGE_SIZE})
+		if err != nil {
+			return nil, err
+		}
+		if list.Error != client.SUCCESS {
+			return nil, &client.ZnsError{Code: list.Error, Message: list.Message}
+		}
+		for _, record := range list.Data {
+			ids = append(ids, record.TemplateID)
+		}
+		if len(list.Data) < PAGE_SIZE || offset+PAGE_SIZE >= list.Metadata.Total {
+			break
+		}
+	}
+
+	templates := make(map[int]client.ZnsTplDetailData, len(ids))
+	for _, id := range ids {
+		detail, err := s.client.GetZnsTemplateDetail(ctx, strconv.Itoa(id))
+		if err != nil {
+			return nil, err
+		}
+		if detail.Error != client.SUCCESS {
+			return nil, &client.ZnsError{Code: detail.Error, Message: detail.Message}
+		}
+		templates[id] = detail.Data
+	}
+	return templates, nil
+}
@@ -0,0 +1,205 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+var otp = client.ZnsTplDetailData{
+	TemplateID:      100,
+	TemplateName:    "OTP",
+	Status:          client.ZNS_TPL_STATUS_ENABLED_NAME,
+	ListParams:      []client.ZnsTplDetailParam{{Name: "otp", Require: true, Type: "STRING", MaxLength: 6}},
+	Timeout:         1800,
+	TemplateQuality: "HIGH",
+	Price:           "300",
+}
+
+var invoice = client.ZnsTplDetailData{
+	TemplateID:   200,
+	TemplateName: "Invoice",
+	Status:       client.ZNS_TPL_STATUS_ENABLED_NAME,
+	ListParams: []client.ZnsTplDetailParam{
+		{Name: "cost", Require: true, Type: "NUMBER"},
+		{Name: "date", Require: true, Type: "DATE"},
+	},
+	Price: "440",
+}
+
+func TestSync(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+	srv.AddTemplate(otp)
+	srv.AddTemplate(invoice)
+
+	syncer := New(&MemoryStore{}, srv.Client())
+	var received []Change
+	syncer.Subscribe(func(ctx context.Context, change Change) {
+		received = append(received, change)
+	})
+	ctx := context.Background()
+
+	// The first sync records the catalog
+	changes, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	template, ok, err := syncer.Template(ctx, 200)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, invoice, template)
+
+	// Nothing changed
+	changes, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	rejected := otp
+	rejected.Status = client.ZNS_TPL_STATUS_REJECTED_NAME
+	rejected.TemplateQuality = "LOW"
+	rejected.Price = "300.0" // Same price
+	srv.AddTemplate(rejected)
+	reordered := invoice
+	reordered.ListParams = []client.ZnsTplDetailParam{invoice.ListParams[1], invoice.ListParams[0]}
+	srv.AddTemplate(reordered)
+	changes, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: CHANGE_STATUS, TemplateID: 100, Before: otp, After: rejected},
+		{Kind: CHANGE_QUALITY, TemplateID: 100, Before: otp, After: rejected},
+	}, changes)
+	assert.Equal(t, changes, received)
+	assert.Equal(t, `template 100 "OTP" status changed from ENABLE to REJECT`, changes[0].String())
+
+	edited := reordered
+	edited.ListParams = append(edited.ListParams, client.ZnsTplDetailParam{Name: "note", Type: "STRING"})
+	edited.Price = "500"
+	edited.Timeout = 3600
+	srv.AddTemplate(edited)
+	srv.RemoveTemplate(100)
+	added := client.ZnsTplDetailData{TemplateID: 300, TemplateName: "Promo", Status: client.ZNS_TPL_STATUS_PENDING_REVIEW_NAME}
+	srv.AddTemplate(added)
+	changes, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	var descriptions []string
+	for _, change := range changes {
+		descriptions = append(descriptions, change.String())
+	}
+	assert.Equal(t, []string{
+		`template 100 "OTP" removed`,
+		`template 200 "Invoice" parameters changed`,
+		`template 200 "Invoice" price changed from 440 to 500`,
+		`template 200 "Invoice" timeout changed from 0 to 3600`,
+		`template 300 "Promo" added with status PENDING_REVIEW`,
+	}, descriptions)
+}
+
+func TestSyncError(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+	srv.AddTemplate(otp)
+
+	syncer := New(&MemoryStore{}, srv.Client())
+	ctx := context.Background()
+	_, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+
+	// A failed sync keeps the previous snapshot, so the change is reported by the next one
+	disabled := otp
+	disabled.Status = client.ZNS_TPL_STATUS_DISABLED_NAME
+	srv.AddTemplate(disabled)
+	srv.InjectError(client.ENDPOINT_TEMPLATE_DETAIL, client.UNKNOWN_ERROR)
+	_, err = syncer.Sync(ctx)
+	assert.ErrorIs(t, err, &client.ZnsError{Code: client.UNKNOWN_ERROR})
+
+	changes, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, CHANGE_STATUS, changes[0].Kind)
+}
+
+// failingStore is a MemoryStore whose saves fail while err is set.
+type failingStore struct {
+	MemoryStore
+	err error
+}
+
+func (s *failingStore) Save(ctx context.Context, snapshot Snapshot) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.MemoryStore.Save(ctx, snapshot)
+}
+
+func TestSyncSaveError(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+	srv.AddTemplate(otp)
+
+	store := &failingStore{}
+	syncer := New(store, srv.Client())
+	var received []Change
+	syncer.Subscribe(func(ctx context.Context, change Change) {
+		received = append(received, change)
+	})
+	ctx := context.Background()
+	_, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+
+	// Changes are delivered before the snapshot is saved, and again by the next sync if saving failed
+	disabled := otp
+	disabled.Status = client.ZNS_TPL_STATUS_DISABLED_NAME
+	srv.AddTemplate(disabled)
+	store.err = errors.New("disk full")
+	changes, err := syncer.Sync(ctx)
+	assert.EqualError(t, err, "disk full")
+	require.Len(t, changes, 1)
+	assert.Equal(t, changes, received)
+
+	store.err = nil
+	changes, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, []Change{changes[0], changes[0]}, received)
+
+	changes, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestSyncPages(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+	for id := 1; id <= 150; id++ {
+		srv.AddTemplate(client.ZnsTplDetailData{TemplateID: id, Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+	}
+
+	syncer := New(&MemoryStore{}, srv.Client())
+	_, err := syncer.Sync(context.Background())
+	require.NoError(t, err)
+	snapshot, err := syncer.Snapshot(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Templates, 150)
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_TEMPLATE_LIST), 2)
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewFileStore(filepath.Join(t.TempDir(), "catalog.json"))
+
+	_, err := store.Load(ctx)
+	assert.ErrorIs(t, err, ErrNoSnapshot)
+
+	snapshot := Snapshot{Templates: map[int]client.ZnsTplDetailData{100: otp}}
+	require.NoError(t, store.Save(ctx, snapshot))
+	loaded, err := store.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, loaded)
+}
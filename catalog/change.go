@@ -0,0 +1,135 @@
+package catalog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+// ChangeKind is the kind of a template change.
+type ChangeKind string
+
+const (
+	CHANGE_ADDED   ChangeKind = "ADDED"   // Template created, or first seen
+	CHANGE_REMOVED ChangeKind = "REMOVED" // Template deleted, or no longer listed
+	CHANGE_STATUS  ChangeKind = "STATUS"  // e.g. approved, rejected or disabled for low quality
+	CHANGE_QUALITY ChangeKind = "QUALITY"
+	CHANGE_PARAMS  ChangeKind = "PARAMS" // Parameters added, removed or redefined
+	CHANGE_PRICE   ChangeKind = "PRICE"
+	CHANGE_TIMEOUT ChangeKind = "TIMEOUT"
+)
+
+// Change is a change of a template between two snapshots.
+// A template with several changed attributes has one Change per attribute.
+type Change struct {
+	Kind       ChangeKind
+	TemplateID int
+	Before     client.ZnsTplDetailData // Zero for CHANGE_ADDED
+	After      client.ZnsTplDetailData // Zero for CHANGE_REMOVED
+}
+
+// String describes the change, e.g. `template 100 "OTP" status changed from ENABLE to REJECT`.
+func (c Change) String() string {
+	name := c.After.TemplateName
+	if c.Kind == CHANGE_REMOVED {
+		name = c.Before.TemplateName
+	}
+	prefix := fmt.Sprintf("template %d %q", c.TemplateID, name)
+
+	switch c.Kind {
+	case CHANGE_ADDED:
+		return fmt.Sprintf("%s added with status %s", prefix, c.After.Status)
+	case CHANGE_REMOVED:
+		return prefix + " removed"
+	case CHANGE_STATUS:
+		return fmt.Sprintf("%s status changed from %s to %s", prefix, c.Before.Status, c.After.Status)
+	case CHANGE_QUALITY:
+		return fmt.Sprintf("%s quality changed from %s to %s", prefix, c.Before.TemplateQuality, c.After.TemplateQuality)
+	case CHANGE_PARAMS:
+		return prefix + " parameters changed"
+	case CHANGE_PRICE:
+		return fmt.Sprintf("%s price changed from %s to %s", prefix, c.Before.Price, c.After.Price)
+	case CHANGE_TIMEOUT:
+		return fmt.Sprintf("%s timeout changed from %d to %d", prefix, c.Before.Timeout, c.After.Timeout)
+	}
+	return prefix + " changed"
+}
+
+// Diff returns the changes from a snapshot to the next one, by template ID.
+func Diff(before, after Snapshot) []Change {
+	ids := make(map[int]bool, len(after.Templates))
+	for id := range before.Templates {
+		ids[id] = true
+	}
+	for id := range after.Templates {
+		ids[id] = true
+	}
+	sorted := make([]int, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Ints(sorted)
+
+	var changes []Change
+	for _, id := range sorted {
+		b, inBefore := before.Templates[id]
+		a, inAfter := after.Templates[id]
+		change := Change{TemplateID: id, Before: b, After: a}
+		switch {
+		case !inBefore:
+			change.Kind = CHANGE_ADDED
+			changes = append(changes, change)
+			continue
+		case !inAfter:
+			change.Kind = CHANGE_REMOVED
+			changes = append(changes, change)
+			continue
+		}
+
+		for _, attribute := range []struct {
+			kind    ChangeKind
+			changed bool
+		}{
+			{CHANGE_STATUS, b.Status != a.Status},
+			{CHANGE_QUALITY, b.TemplateQuality != a.TemplateQuality},
+			{CHANGE_PARAMS, !sameParams(b.ListParams, a.ListParams)},
+			{CHANGE_PRICE, !samePrice(b.Price, a.Price)},
+			{CHANGE_TIMEOUT, b.Timeout != a.Timeout},
+		} {
+			if attribute.changed {
+				change.Kind = attribute.kind
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes
+}
+
+// sameParams compares parameters regardless of their order.
+func sameParams(a, b []client.ZnsTplDetailParam) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	byName := make(map[string]client.ZnsTplDetailParam, len(a))
+	for _, param := range a {
+		byName[param.Name] = param
+	}
+	for _, param := range b {
+		if other, ok := byName[param.Name]; !ok || !reflect.DeepEqual(param, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// samePrice compares prices numerically when they parse, e.g. "300" and "300.0" are the same.
+func samePrice(a, b string) bool {
+	pa, errA := client.ParsePrice(a)
+	pb, errB := client.ParsePrice(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return pa == pb
+}
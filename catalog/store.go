@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/x/atomicfile"
+)
+
+// ErrNoSnapshot is returned by Store.Load before the first snapshot is saved.
+var ErrNoSnapshot = errors.New("catalog: no snapshot")
+
+// Snapshot is the templates of an OA at the time of a sync.
+type Snapshot struct {
+	Templates map[int]client.ZnsTplDetailData `json:"templates"` // Template details by template ID
+	SyncedAt  time.Time                       `json:"synced_at"`
+}
+
+// Store persists the last snapshot of a catalog.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the last saved snapshot, or ErrNoSnapshot.
+	Load(ctx context.Context) (Snapshot, error)
+	// Save replaces the last snapshot.
+	Save(ctx context.Context, snapshot Snapshot) error
+}
+
+// MemoryStore is a Store keeping the snapshot in memory, e.g. for tests.
+type MemoryStore struct {
+	mu       sync.Mutex
+	snapshot *Snapshot
+}
+
+func (s *MemoryStore) Load(ctx context.Context) (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot == nil {
+		return Snapshot{}, ErrNoSnapshot
+	}
+	return *s.snapshot, nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshot = &snapshot
+	return nil
+}
+
+// FileStore is a Store keeping the snapshot as JSON in a file, atomically rewritten on every save.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore creates a file store at path. The file is created on the first save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Load(ctx context.Context) (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, ErrNoSnapshot
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+func (s *FileStore) Save(ctx context.Context, snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, data, 0o600)
+}
@@ -2,20 +2,69 @@ package client
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
+	"time"
 )
 
+// REFRESH_TOKEN_LIFETIME is how long a refresh token stays valid after it is issued, about three months.
+// Zalo does not return it with the token, so it is documented rather than reported.
+const REFRESH_TOKEN_LIFETIME = 90 * 24 * time.Hour
+
+// AccessToken is an OA access token with its refresh token.
+//
+// ExpiresIn is relative to ObtainedAt, the time the token was issued.
+// ExpiresAt and RefreshTokenExpiresAt are absolute, so a token stored as JSON
+// keeps its meaning after a restart. A zero time means the expiry is unknown.
 type AccessToken struct {
-	AccessToken  string `json:"access_token" mapstructure:"access_token"`
-	RefreshToken string `json:"refresh_token" mapstructure:"refresh_token"`
-	ExpiresIn    int    `json:"expires_in" mapstructure:"expires_in"`
+	AccessToken           string    `json:"access_token" mapstructure:"access_token"`
+	RefreshToken          string    `json:"refresh_token" mapstructure:"refresh_token"`
+	ExpiresIn             int       `json:"expires_in" mapstructure:"expires_in"`
+	ObtainedAt            time.Time `json:"obtained_at" mapstructure:"obtained_at"`
+	ExpiresAt             time.Time `json:"expires_at" mapstructure:"expires_at"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at" mapstructure:"refresh_token_expires_at"`
+}
+
+// withExpiry returns the token with its expiry times computed from ExpiresIn,
+// as if it had been obtained at obtainedAt. Times that are already set are kept.
+func (t AccessToken) withExpiry(obtainedAt time.Time) AccessToken {
+	if t.ObtainedAt.IsZero() {
+		t.ObtainedAt = obtainedAt
+	}
+	if t.ExpiresAt.IsZero() && t.ExpiresIn > 0 {
+		t.ExpiresAt = t.ObtainedAt.Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	if t.RefreshTokenExpiresAt.IsZero() && t.RefreshToken != "" {
+		t.RefreshTokenExpiresAt = t.ObtainedAt.Add(REFRESH_TOKEN_LIFETIME)
+	}
+	return t
+}
+
+// Valid reports whether the access token is set and has not expired.
+// A token whose expiry is unknown is considered valid.
+func (t AccessToken) Valid() bool {
+	return t.AccessToken != "" && (t.ExpiresAt.IsZero() || time.Now().Before(t.ExpiresAt))
+}
+
+// ExpiresWithin reports whether the access token expires within d from now.
+// It returns false if the expiry is unknown.
+func (t AccessToken) ExpiresWithin(d time.Duration) bool {
+	return !t.ExpiresAt.IsZero() && time.Until(t.ExpiresAt) <= d
+}
+
+// RefreshTokenValid reports whether the refresh token is set and has not expired.
+// A refresh token whose expiry is unknown is considered valid.
+func (t AccessToken) RefreshTokenValid() bool {
+	return t.RefreshToken != "" && (t.RefreshTokenExpiresAt.IsZero() || time.Now().Before(t.RefreshTokenExpiresAt))
+}
+
+// RefreshTokenExpiresWithin reports whether the refresh token expires within d from now.
+// It returns false if the expiry is unknown.
+func (t AccessToken) RefreshTokenExpiresWithin(d time.Duration) bool {
+	return !t.RefreshTokenExpiresAt.IsZero() && time.Until(t.RefreshTokenExpiresAt) <= d
 }
 
 type AccessTokenRequest struct {
@@ -31,11 +80,10 @@ type ErrorResp struct {
 // RequestAccessToken exchanges an authorization code for an access token.
 // It sends a POST request to the Zalo API using the provided context and request data.
 // The request includes the authorization code, app ID, and code verifier.
-// On success, it returns the access token, refresh token, and expiration time.
+// On success, it returns the access token, refresh token, and expiration times.
+// If the Zalo OAuth server rejects the request, it returns an *OAuthError.
 // If an error occurs during the request or response processing, it returns the error.
 func (z *ZaloClient) RequestAccessToken(ctx context.Context, request AccessTokenRequest) (AccessToken, error) {
-	var token AccessToken
-
 	// Set up the form data
 	formData := url.Values{}
 	formData.Set("code", request.Code)
@@ -43,59 +91,35 @@ func (z *ZaloClient) RequestAccessToken(ctx context.Context, request AccessToken
 	formData.Set("grant_type", "authorization_code")
 	formData.Set("code_verifier", z.codeVerifier)
 
-	req, err := http.NewRequest("POST", ENDPOINT_GET_ACCESS_TOKEN, strings.NewReader(formData.Encode()))
-	if err != nil {
-		z.GetLogger().ErrorContext(ctx, "Error creating request:", slog.Any("err", err))
-		return token, err
-	}
-	// Set headers
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("secret_key", z.secretKey)
-	resp, err := z.GetHTTPClient().Do(req)
-	if err != nil {
-		z.GetLogger().ErrorContext(ctx, "Error sending request:", slog.Any("err", err))
-		return token, err
-	}
-	defer resp.Body.Close()
-
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		z.GetLogger().ErrorContext(ctx, "Error reading response:", slog.Any("err", err))
-		return token, err
-	}
-
-	var respBody map[string]string
-	err = json.Unmarshal(body, &respBody)
-	if err != nil {
-		var errResp ErrorResp
-		err = json.Unmarshal(body, &errResp)
-		if err != nil {
-			z.GetLogger().ErrorContext(ctx, "Error unmarshalling response:", slog.Any("err", err))
-			return token, err
-		}
-		err = fmt.Errorf("code: %d, description: %s", errResp.Error, errResp.ErrorDescription)
-		z.GetLogger().ErrorContext(ctx, "Error:", slog.Any("err", err))
-		return token, err
-	}
-
-	token.AccessToken = respBody["access_token"]
-	token.RefreshToken = respBody["refresh_token"]
-	token.ExpiresIn, _ = strconv.Atoi(respBody["expires_in"])
-
-	return token, nil
+	return z.requestToken(ctx, formData)
 }
 
+// RefreshAccessToken exchanges a refresh token for a new access token.
+// It sends a POST request to the Zalo API using the provided context and request data.
+// The request includes the refresh token and app ID.
+// On success, it returns the new access token, refresh token, and expiration times.
+// If the Zalo OAuth server rejects the request, it returns an *OAuthError; when the refresh
+// token itself is rejected, it also calls the callback registered with OnReauthorizationRequired.
+// If an error occurs during the request or response processing, it returns the error.
 func (z *ZaloClient) RefreshAccessToken(ctx context.Context, request AccessTokenRequest) (AccessToken, error) {
-	var token AccessToken
-
 	// Set up the form data
 	formData := url.Values{}
 	formData.Set("refresh_token", request.RefreshToken)
 	formData.Set("app_id", z.appID)
 	formData.Set("grant_type", "refresh_token")
 
-	req, err := http.NewRequest("POST", ENDPOINT_GET_ACCESS_TOKEN, strings.NewReader(formData.Encode()))
+	token, err := z.requestToken(ctx, formData)
+	if refreshTokenRejected(err) {
+		z.reauthorizationRequired(ctx, request.RefreshToken, err)
+	}
+	return token, err
+}
+
+// requestToken sends a form to the OAuth access token endpoint and decodes the token response.
+func (z *ZaloClient) requestToken(ctx context.Context, formData url.Values) (AccessToken, error) {
+	var token AccessToken
+
+	req, err := http.NewRequest("POST", z.endpoint(ENDPOINT_GET_ACCESS_TOKEN), strings.NewReader(formData.Encode()))
 	if err != nil {
 		z.GetLogger().ErrorContext(ctx, "Error creating request:", slog.Any("err", err))
 		return token, err
@@ -103,7 +127,7 @@ func (z *ZaloClient) RefreshAccessToken(ctx context.Context, request AccessToken
 	// Set headers
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Add("secret_key", z.secretKey)
-	resp, err := z.GetHTTPClient().Do(req)
+	resp, err := z.do(ctx, ENDPOINT_GROUP_OAUTH, req)
 	if err != nil {
 		z.GetLogger().ErrorContext(ctx, "Error sending request:", slog.Any("err", err))
 		return token, err
@@ -117,23 +141,11 @@ func (z *ZaloClient) RefreshAccessToken(ctx context.Context, request AccessToken
 		return token, err
 	}
 
-	var respBody map[string]string
-	err = json.Unmarshal(body, &respBody)
+	token, err = decodeTokenResponse(body)
 	if err != nil {
-		var errResp ErrorResp
-		err = json.Unmarshal(body, &errResp)
-		if err != nil {
-			z.GetLogger().ErrorContext(ctx, "Error unmarshalling response:", slog.Any("err", err))
-			return token, err
-		}
-		err = fmt.Errorf("code: %d, description: %s", errResp.Error, errResp.ErrorDescription)
 		z.GetLogger().ErrorContext(ctx, "Error:", slog.Any("err", err))
 		return token, err
 	}
 
-	token.AccessToken = respBody["access_token"]
-	token.RefreshToken = respBody["refresh_token"]
-	token.ExpiresIn, _ = strconv.Atoi(respBody["expires_in"])
-
-	return token, nil
+	return token.withExpiry(time.Now()), nil
 }
@@ -0,0 +1,147 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func TestRequestAccessToken(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+
+	zc := srv.Client()
+	token, err := zc.RequestAccessToken(context.Background(), client.AccessTokenRequest{Code: "auth-code"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.AccessToken)
+	assert.NotEmpty(t, token.RefreshToken)
+	assert.Equal(t, zalotest.TOKEN_EXPIRES_IN, token.ExpiresIn)
+
+	requests := srv.RequestsTo(client.ENDPOINT_GET_ACCESS_TOKEN)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "authorization_code", requests[0].Form["grant_type"])
+	assert.Equal(t, "auth-code", requests[0].Form["code"])
+	assert.Equal(t, zalotest.APP_ID, requests[0].Form["app_id"])
+	assert.Equal(t, zalotest.CODE_VERIFIER, requests[0].Form["code_verifier"])
+	assert.Equal(t, zalotest.SECRET_KEY, requests[0].Header.Get("secret_key"))
+}
+
+func TestRefreshAccessToken(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+
+	zc := srv.Client()
+	first := zc.GetAccessToken()
+
+	token, err := zc.RefreshAccessToken(context.Background(), client.AccessTokenRequest{RefreshToken: first.RefreshToken})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.AccessToken, token.AccessToken)
+
+	_, err = zc.RefreshAccessToken(context.Background(), client.AccessTokenRequest{RefreshToken: first.RefreshToken})
+	assert.Error(t, err, "a refresh token can only be used once")
+}
+
+func TestRequestAccessTokenInjectedError(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+
+	zc := srv.Client()
+	srv.InjectError(client.ENDPOINT_GET_ACCESS_TOKEN, client.APPLICATION_SECRET_KEY_INVALID)
+
+	_, err := zc.RequestAccessToken(context.Background(), client.AccessTokenRequest{Code: "auth-code"})
+	var oauthErr *client.OAuthError
+	require.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, client.APPLICATION_SECRET_KEY_INVALID, oauthErr.Code)
+}
+
+func TestRequestAccessTokenSetsExpiry(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+
+	before := time.Now()
+	token, err := srv.Client().RequestAccessToken(context.Background(), client.AccessTokenRequest{Code: "auth-code"})
+	require.NoError(t, err)
+
+	assert.False(t, token.ObtainedAt.Before(before))
+	assert.Equal(t, token.ObtainedAt.Add(zalotest.TOKEN_EXPIRES_IN*time.Second), token.ExpiresAt)
+	assert.Equal(t, token.ObtainedAt.Add(client.REFRESH_TOKEN_LIFETIME), token.RefreshTokenExpiresAt)
+	assert.True(t, token.Valid())
+	assert.True(t, token.RefreshTokenValid())
+	assert.False(t, token.ExpiresWithin(time.Hour))
+	assert.True(t, token.ExpiresWithin(26*time.Hour))
+}
+
+func TestAccessTokenExpiry(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name          string
+		token         client.AccessToken
+		valid         bool
+		within        bool
+		refreshValid  bool
+		refreshWithin bool
+	}{
+		{"empty", client.AccessToken{}, false, false, false, false},
+		{"unknown expiry", client.AccessToken{AccessToken: "at", RefreshToken: "rt"}, true, false, true, false},
+		{"fresh", client.AccessToken{
+			AccessToken: "at", RefreshToken: "rt",
+			ExpiresAt: now.Add(24 * time.Hour), RefreshTokenExpiresAt: now.Add(80 * 24 * time.Hour),
+		}, true, false, true, false},
+		{"expiring", client.AccessToken{
+			AccessToken: "at", RefreshToken: "rt",
+			ExpiresAt: now.Add(5 * time.Minute), RefreshTokenExpiresAt: now.Add(24 * time.Hour),
+		}, true, true, true, true},
+		{"expired", client.AccessToken{
+			AccessToken: "at", RefreshToken: "rt",
+			ExpiresAt: now.Add(-time.Minute), RefreshTokenExpiresAt: now.Add(-time.Minute),
+		}, false, true, false, true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, tt.token.Valid(), tt.name)
+		assert.Equal(t, tt.within, tt.token.ExpiresWithin(time.Hour), tt.name)
+		assert.Equal(t, tt.refreshValid, tt.token.RefreshTokenValid(), tt.name)
+		assert.Equal(t, tt.refreshWithin, tt.token.RefreshTokenExpiresWithin(7*24*time.Hour), tt.name)
+	}
+}
+
+func TestAccessTokenJSONRoundTrip(t *testing.T) {
+	obtainedAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	token := client.AccessToken{
+		AccessToken:           "at",
+		RefreshToken:          "rt",
+		ExpiresIn:             90000,
+		ObtainedAt:            obtainedAt,
+		ExpiresAt:             obtainedAt.Add(90000 * time.Second),
+		RefreshTokenExpiresAt: obtainedAt.Add(client.REFRESH_TOKEN_LIFETIME),
+	}
+
+	data, err := json.Marshal(token)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"expires_at":"2024-05-02T09:00:00Z"`)
+
+	var decoded client.AccessToken
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, token, decoded)
+
+	zc := client.NewZaloClient("app-id", "secret", "verifier")
+	zc.SetAccessToken(decoded)
+	assert.Equal(t, token, zc.GetAccessToken(), "stored expiry times are kept")
+}
+
+func TestSetAccessTokenWithoutExpiry(t *testing.T) {
+	zc := client.NewZaloClient("app-id", "secret", "verifier")
+	zc.SetAccessToken(client.AccessToken{AccessToken: "at", RefreshToken: "rt", ExpiresIn: 3600})
+
+	token := zc.GetAccessToken()
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, time.Minute)
+	assert.WithinDuration(t, time.Now().Add(client.REFRESH_TOKEN_LIFETIME), token.RefreshTokenExpiresAt, time.Minute)
+}
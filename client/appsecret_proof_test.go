@@ -0,0 +1,49 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func TestGetAppSecretProof(t *testing.T) {
+	// echo -n "access-token" | openssl dgst -sha256 -hmac "secret-key"
+	assert.Equal(t,
+		"dcd532127a68dfdf678511783010caea449adce2a773ef8c52f86e27580f646c",
+		client.GetAppSecretProof("access-token", "secret-key"))
+	assert.NotEqual(t, client.GetAppSecretProof("access-token", "secret-key"), client.GetAppSecretProof("access-token", "other-key"))
+}
+
+func TestUseAppSecretProof(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+	srv.RequireAppSecretProof(true)
+
+	zc := srv.Client()
+	ctx := context.Background()
+
+	response, err := zc.GetZnsTemplateList(ctx, client.ZnsTplListRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, client.APP_SECRET_PROOF_INVALID, response.Error)
+
+	zc.UseAppSecretProof(true)
+	response, err = zc.GetZnsTemplateList(ctx, client.ZnsTplListRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+
+	requests := srv.RequestsTo(client.ENDPOINT_TEMPLATE_LIST)
+	require.Len(t, requests, 2)
+	assert.Equal(t,
+		client.GetAppSecretProof(zc.GetAccessToken().AccessToken, zalotest.SECRET_KEY),
+		requests[1].Header.Get("appsecret_proof"))
+
+	_, err = zc.RequestAccessToken(ctx, client.AccessTokenRequest{Code: "code"})
+	require.NoError(t, err)
+	assert.Empty(t, srv.RequestsTo(client.ENDPOINT_GET_ACCESS_TOKEN)[0].Header.Get("appsecret_proof"),
+		"OAuth requests are not authenticated with an access token")
+}
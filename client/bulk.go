@@ -0,0 +1,269 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExhausted is returned when the quota tracker of the client reports no quota left today.
+var ErrQuotaExhausted = errors.New("zalo: ZNS quota exhausted")
+
+// BulkStoppedError is returned by SendBulk when an OA-level error or an exhausted quota stops the run.
+// Messages not sent yet are reported as skipped with this error.
+type BulkStoppedError struct {
+	Code int   // The OA-level error code, or 0 when stopped by Err
+	Err  error // The error that stopped the run when Code is 0
+}
+
+func (e *BulkStoppedError) Error() string {
+	if e.Code != SUCCESS {
+		return fmt.Sprintf("zalo: bulk send stopped by error code %d", e.Code)
+	}
+	return fmt.Sprintf("zalo: bulk send stopped: %v", e.Err)
+}
+
+func (e *BulkStoppedError) Unwrap() error {
+	return e.Err
+}
+
+// bulkStopCodes are error codes that affect every message of the OA,
+// so there is no point sending the next ones.
+var bulkStopCodes = map[int]bool{
+	APPLICATION_INVALID:               true,
+	APPLICATION_NOT_EXISTED:           true,
+	APPLICATION_NOT_ACTIVATED:         true,
+	APPLICATION_SECRET_KEY_INVALID:    true,
+	APPLICATION_NOT_LINK_TO_ANY_OA:    true,
+	OUT_OF_QUOTA:                      true,
+	ACCESS_TOKEN_INVALID:              true,
+	APP_SECRET_PROOF_INVALID:          true,
+	OA_ID_INVALID:                     true,
+	OUT_OF_QUOTA_DEV:                  true,
+	OA_NO_PERMISSION_SEND_ZNS_MESSAGE: true,
+	OA_BLOCKED_SEND_ZNS_MESSAGE:       true,
+	OA_EXCEED_DAILY_MSG_LIMIT:         true,
+	OA_EXCEED_MONTHLY_MSG_LIMIT:       true,
+	ZNS_OUT_OF_DAILY_QUOTA:            true,
+}
+
+// BulkOptions configures SendBulk. Zero values are replaced by defaults.
+type BulkOptions struct {
+	Workers     int                     // Number of concurrent senders, default 10
+	MaxAttempts int                     // Attempts per message on errors before sending and UNKNOWN_ERROR, default 3
+	RetryDelay  time.Duration           // Delay before the first retry, doubled on each retry, default 1 second
+	OnResult    func(result BulkResult) // Called once per message; calls are serialized
+}
+
+// BulkResult is the outcome of one message of a bulk send.
+type BulkResult struct {
+	Request  ZnsSendMsgRequest // The request, with the tracking ID of every attempt once sent
+	MsgID    string            // The message ID when sent
+	Error    int               // The Zalo error code of the last attempt, SUCCESS when sent
+	Err      error             // The request error of the last attempt, or why the message was skipped
+	Attempts int               // Number of attempts, 0 when skipped
+}
+
+// Sent reports whether the message was accepted by Zalo.
+func (r BulkResult) Sent() bool {
+	return r.Err == nil && r.Error == SUCCESS
+}
+
+// BulkSummary aggregates the results of a bulk send.
+type BulkSummary struct {
+	Total    int         // Messages read from the stream
+	Sent     int         // Messages accepted by Zalo
+	Failed   int         // Messages rejected by Zalo or failed after every attempt
+	Skipped  int         // Messages not sent because the run stopped
+	Errors   map[int]int // Number of failed messages per Zalo error code
+	Duration time.Duration
+}
+
+// SendBulk sends every ZNS message received from requests until the channel is closed,
+// with a pool of concurrent workers.
+//
+// Requests go through the rate limiter of the send endpoint group like any SendZnsMessage call.
+// UNKNOWN_ERROR responses and errors proving that the message never reached Zalo, i.e. an open
+// circuit, a rate limiter error or a failed dial, are retried with exponential backoff.
+// Other errors, e.g. a timeout after the request was written, are not retried, since Zalo may
+// have accepted the message and does not deduplicate sends.
+// The run stops on the first OA-level error, e.g. OA_BLOCKED_SEND_ZNS_MESSAGE or OUT_OF_QUOTA,
+// when the quota tracker of the client reports no quota left, or when a message would exceed the
+// budget of the spend tracker of the client: the remaining requests are
+// still read from the channel and reported as skipped, and a *BulkStoppedError is returned.
+// If ctx is done, SendBulk stops reading requests and returns the context error.
+//
+// OnResult, if set, is called once per message read. The summary is returned in every case.
+func (z *ZaloClient) SendBulk(ctx context.Context, requests <-chan ZnsSendMsgRequest, options BulkOptions) (BulkSummary, error) {
+	if options.Workers <= 0 {
+		options.Workers = 10
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 3
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = time.Second
+	}
+
+	run := &bulkRun{
+		client:  z,
+		options: options,
+		summary: BulkSummary{Errors: make(map[int]int)},
+	}
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run.work(ctx, requests)
+		}()
+	}
+	wg.Wait()
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	run.summary.Duration = time.Since(start)
+	if run.stopErr != nil {
+		return run.summary, run.stopErr
+	}
+	return run.summary, ctx.Err()
+}
+
+// bulkRun is the shared state of the workers of a SendBulk call.
+type bulkRun struct {
+	client  *ZaloClient
+	options BulkOptions
+
+	mu      sync.Mutex
+	summary BulkSummary
+	stopErr error
+}
+
+func (r *bulkRun) work(ctx context.Context, requests <-chan ZnsSendMsgRequest) {
+	for {
+		var request ZnsSendMsgRequest
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return
+		case request, ok = <-requests:
+			if !ok {
+				return
+			}
+		}
+
+		if err := r.stopped(); err != nil {
+			r.report(BulkResult{Request: request, Err: err})
+			continue
+		}
+		if tracker := r.client.GetQuotaTracker(); tracker != nil && tracker.Exhausted() {
+			r.report(BulkResult{Request: request, Err: r.stop(&BulkStoppedError{Err: ErrQuotaExhausted})})
+			continue
+		}
+
+		result := r.send(ctx, request)
+		switch {
+		case bulkStopCodes[result.Error]:
+			r.stop(&BulkStoppedError{Code: result.Error})
+		case errors.Is(result.Err, ErrBudgetExceeded):
+			// Not sent, like the messages after it
+			result = BulkResult{Request: result.Request, Err: r.stop(&BulkStoppedError{Err: result.Err})}
+		}
+		r.report(result)
+	}
+}
+
+// send sends one message, retrying failures that cannot have sent it.
+// Every attempt has the same tracking ID, so that the delivery of the message can be matched
+// to its request whatever attempt sent it.
+func (r *bulkRun) send(ctx context.Context, request ZnsSendMsgRequest) BulkResult {
+	if request.TrackingID == "" {
+		request.TrackingID = NewTrackingID()
+	}
+	result := BulkResult{Request: request}
+	delay := r.options.RetryDelay
+
+	for result.Attempts < r.options.MaxAttempts {
+		result.Attempts++
+		response, err := r.client.SendZnsMessage(ctx, request)
+		result.MsgID = response.Data.MsgID
+		result.Error = response.Error
+		result.Err = err
+
+		transient := (err != nil && ctx.Err() == nil && notSent(err)) ||
+			(err == nil && response.Error == UNKNOWN_ERROR)
+		if !transient || result.Attempts == r.options.MaxAttempts {
+			break
+		}
+
+		wait := delay
+		var openErr *CircuitOpenError
+		if errors.As(err, &openErr) && openErr.RetryAfter > wait {
+			wait = openErr.RetryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+	return result
+}
+
+// stop records the first error stopping the run and returns it.
+func (r *bulkRun) stop(err error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopErr == nil {
+		r.stopErr = err
+	}
+	return r.stopErr
+}
+
+// stopped returns the error that stopped the run, or nil if it is running.
+func (r *bulkRun) stopped() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopErr
+}
+
+// report aggregates a result and passes it to OnResult.
+func (r *bulkRun) report(result BulkResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.summary.Total++
+	switch {
+	case result.Attempts == 0:
+		r.summary.Skipped++
+	case result.Sent():
+		r.summary.Sent++
+	default:
+		r.summary.Failed++
+		if result.Err == nil {
+			r.summary.Errors[result.Error]++
+		}
+	}
+
+	if r.options.OnResult != nil {
+		r.options.OnResult(result)
+	}
+}
+
+// notSent reports whether err proves that the request never reached Zalo:
+// the circuit was open, the rate limiter failed or the connection could not be dialed.
+func notSent(err error) bool {
+	var openErr *CircuitOpenError
+	var limitErr *limiterError
+	var opErr *net.OpError
+	return errors.As(err, &openErr) || errors.As(err, &limitErr) || (errors.As(err, &opErr) && opErr.Op == "dial")
+}
@@ -0,0 +1,193 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+func bulkRequests(n int) <-chan client.ZnsSendMsgRequest {
+	requests := make(chan client.ZnsSendMsgRequest)
+	go func() {
+		defer close(requests)
+		for i := 0; i < n; i++ {
+			requests <- client.ZnsSendMsgRequest{
+				Phone:        "84987654321",
+				TemplateID:   "100",
+				TemplateData: map[string]string{"cost": "1"},
+				TrackingID:   fmt.Sprintf("bulk-%d", i),
+			}
+		}
+	}()
+	return requests
+}
+
+func TestSendBulk(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+
+	var results []client.BulkResult
+	summary, err := zc.SendBulk(context.Background(), bulkRequests(50), client.BulkOptions{
+		Workers:  5,
+		OnResult: func(result client.BulkResult) { results = append(results, result) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 50, summary.Total)
+	assert.Equal(t, 50, summary.Sent)
+	assert.Len(t, results, 50)
+	assert.Len(t, srv.Messages(), 50)
+	for _, result := range results {
+		assert.True(t, result.Sent())
+		assert.NotEmpty(t, result.MsgID)
+		assert.Equal(t, 1, result.Attempts)
+	}
+}
+
+func TestSendBulkRetriesUnknownError(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.UNKNOWN_ERROR)
+	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.UNKNOWN_ERROR)
+
+	var results []client.BulkResult
+	summary, err := zc.SendBulk(context.Background(), bulkRequests(1), client.BulkOptions{
+		RetryDelay: time.Millisecond,
+		OnResult:   func(result client.BulkResult) { results = append(results, result) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Sent)
+	require.Len(t, results, 1)
+	assert.Equal(t, 3, results[0].Attempts)
+}
+
+func TestSendBulkRetriesWithSameTrackingID(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.UNKNOWN_ERROR)
+
+	requests := make(chan client.ZnsSendMsgRequest, 1)
+	requests <- client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "100", TemplateData: map[string]string{"cost": "1"}}
+	close(requests)
+
+	var results []client.BulkResult
+	_, err := zc.SendBulk(context.Background(), requests, client.BulkOptions{
+		RetryDelay: time.Millisecond,
+		OnResult:   func(result client.BulkResult) { results = append(results, result) },
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Attempts)
+	trackingID := results[0].Request.TrackingID
+	assert.NotEmpty(t, trackingID)
+
+	sends := srv.RequestsTo(client.ENDPOINT_MESSAGE_SEND)
+	require.Len(t, sends, 2)
+	for _, send := range sends {
+		var body struct {
+			TrackingID string `json:"tracking_id"`
+		}
+		require.NoError(t, json.Unmarshal(send.Body, &body))
+		assert.Equal(t, trackingID, body.TrackingID)
+	}
+}
+
+// lostResponseTransport lets the server handle every request, but fails POST requests as if
+// the connection was reset before the response arrived.
+type lostResponseTransport struct {
+	next http.RoundTripper
+}
+
+func (t lostResponseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || req.Method != http.MethodPost {
+		return resp, err
+	}
+	resp.Body.Close()
+	return nil, io.ErrUnexpectedEOF
+}
+
+func TestSendBulkDoesNotRetryAfterSending(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+	zc.UseHTTPClient(&http.Client{Transport: lostResponseTransport{next: srv.Server.Client().Transport}})
+
+	var results []client.BulkResult
+	summary, err := zc.SendBulk(context.Background(), bulkRequests(1), client.BulkOptions{
+		Workers:    1,
+		RetryDelay: time.Millisecond,
+		OnResult:   func(result client.BulkResult) { results = append(results, result) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Failed)
+	require.Len(t, results, 1)
+	assert.Equal(t, 1, results[0].Attempts)
+	assert.ErrorIs(t, results[0].Err, io.ErrUnexpectedEOF)
+	assert.Len(t, srv.Messages(), 1)
+}
+
+func TestSendBulkStopsOnOAError(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.OA_BLOCKED_SEND_ZNS_MESSAGE)
+
+	var skipped int
+	summary, err := zc.SendBulk(context.Background(), bulkRequests(20), client.BulkOptions{
+		Workers: 1,
+		OnResult: func(result client.BulkResult) {
+			if result.Attempts == 0 {
+				skipped++
+				assert.Error(t, result.Err)
+			}
+		},
+	})
+
+	var stopErr *client.BulkStoppedError
+	require.True(t, errors.As(err, &stopErr))
+	assert.Equal(t, client.OA_BLOCKED_SEND_ZNS_MESSAGE, stopErr.Code)
+	assert.Equal(t, 20, summary.Total)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 19, summary.Skipped)
+	assert.Equal(t, 19, skipped)
+	assert.Equal(t, map[int]int{client.OA_BLOCKED_SEND_ZNS_MESSAGE: 1}, summary.Errors)
+	assert.Empty(t, srv.Messages())
+}
+
+func TestSendBulkStopsWhenQuotaExhausted(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	srv.SetQuota(500, 3)
+	zc := srv.Client()
+	zc.UseQuotaTracker(client.NewQuotaTracker())
+
+	summary, err := zc.SendBulk(context.Background(), bulkRequests(10), client.BulkOptions{Workers: 1})
+	assert.ErrorIs(t, err, client.ErrQuotaExhausted)
+	assert.Equal(t, 3, summary.Sent)
+	assert.Equal(t, 7, summary.Skipped)
+	assert.Len(t, srv.Messages(), 3)
+}
+
+func TestSendBulkCanceled(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := zc.SendBulk(ctx, make(chan client.ZnsSendMsgRequest), client.BulkOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
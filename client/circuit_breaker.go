@@ -0,0 +1,235 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched by the error returned when a request is rejected by an open circuit breaker.
+var ErrCircuitOpen = errors.New("zalo: circuit breaker is open")
+
+// CircuitOpenError is returned instead of sending a request while the circuit breaker is open.
+// It matches ErrCircuitOpen with errors.Is.
+type CircuitOpenError struct {
+	RetryAfter time.Duration // Time left before the breaker lets probe requests through
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrCircuitOpen, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	CIRCUIT_CLOSED    CircuitState = iota // Requests are sent normally
+	CIRCUIT_OPEN                          // Requests fail fast with ErrCircuitOpen
+	CIRCUIT_HALF_OPEN                     // A limited number of probe requests are sent
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CIRCUIT_CLOSED:
+		return "CLOSED"
+	case CIRCUIT_OPEN:
+		return "OPEN"
+	case CIRCUIT_HALF_OPEN:
+		return "HALF_OPEN"
+	}
+	return "UNKNOWN"
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker. Zero values are replaced by defaults.
+type CircuitBreakerConfig struct {
+	FailureRatio   float64                     // Ratio of failed requests that trips the breaker, default 0.5
+	MinRequests    int                         // Requests needed in a window before the ratio is evaluated, default 10
+	Window         time.Duration               // Length of the window failures are counted in while closed, default 1 minute
+	OpenTimeout    time.Duration               // Time the breaker stays open before half-opening, default 30 seconds
+	HalfOpenProbes int                         // Probe requests that must all succeed to close the breaker, default 1
+	OnStateChange  func(from, to CircuitState) // Called after every state change, e.g. for alerting
+	now            func() time.Time
+}
+
+// CircuitBreaker stops sending requests to the Zalo business API while it is failing.
+//
+// A request fails if it returns a network error, a 5xx HTTP status or an UNKNOWN_ERROR response.
+// While closed, the breaker trips to open when the failure ratio in the current window reaches
+// FailureRatio. While open, requests fail fast with a *CircuitOpenError. After OpenTimeout the
+// breaker half-opens and lets HalfOpenProbes requests through: if they all succeed the breaker
+// closes, if any fails it opens again.
+//
+// A CircuitBreaker is safe for concurrent use and may be shared by several clients.
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	config      CircuitBreakerConfig
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probing     int // probe requests in flight while half-open
+	probesOK    int // successful probe requests while half-open
+	changes     []circuitChange
+}
+
+// circuitChange is a state change waiting to be reported to OnStateChange.
+type circuitChange struct {
+	from, to CircuitState
+}
+
+// circuitResult is the outcome of a request as seen by the circuit breaker.
+type circuitResult int
+
+const (
+	circuitSuccess circuitResult = iota
+	circuitFailure
+	circuitIgnored // e.g. the caller canceled the request
+)
+
+// NewCircuitBreaker creates a closed circuit breaker.
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureRatio <= 0 {
+		config.FailureRatio = 0.5
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+	if config.now == nil {
+		config.now = time.Now
+	}
+	return &CircuitBreaker{
+		config:      config,
+		state:       CIRCUIT_CLOSED,
+		windowStart: config.now(),
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.advance(b.config.now())
+	return b.state
+}
+
+// UseCircuitBreaker sets the circuit breaker guarding requests to the Zalo business API.
+// OAuth requests are not guarded. Passing nil removes the circuit breaker.
+func (z *ZaloClient) UseCircuitBreaker(breaker *CircuitBreaker) {
+	z.circuitBreaker = breaker
+}
+
+// GetCircuitBreaker returns the circuit breaker of the client, or nil if there is none.
+func (z *ZaloClient) GetCircuitBreaker() *CircuitBreaker {
+	return z.circuitBreaker
+}
+
+// allow reports whether a request may be sent, reserving a probe slot while half-open.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.unlock()
+
+	now := b.config.now()
+	b.advance(now)
+
+	switch b.state {
+	case CIRCUIT_OPEN:
+		return &CircuitOpenError{RetryAfter: b.openedAt.Add(b.config.OpenTimeout).Sub(now)}
+	case CIRCUIT_HALF_OPEN:
+		if b.probing+b.probesOK >= b.config.HalfOpenProbes {
+			return &CircuitOpenError{}
+		}
+		b.probing++
+	}
+	return nil
+}
+
+// record registers the outcome of a request allowed by allow.
+func (b *CircuitBreaker) record(result circuitResult) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	now := b.config.now()
+	switch b.state {
+	case CIRCUIT_CLOSED:
+		if result == circuitIgnored {
+			return
+		}
+		if now.Sub(b.windowStart) >= b.config.Window {
+			b.windowStart = now
+			b.requests, b.failures = 0, 0
+		}
+		b.requests++
+		if result == circuitFailure {
+			b.failures++
+		}
+		if b.requests >= b.config.MinRequests &&
+			float64(b.failures)/float64(b.requests) >= b.config.FailureRatio {
+			b.setState(CIRCUIT_OPEN, now)
+		}
+	case CIRCUIT_HALF_OPEN:
+		if b.probing > 0 {
+			b.probing--
+		}
+		switch result {
+		case circuitFailure:
+			b.setState(CIRCUIT_OPEN, now)
+		case circuitSuccess:
+			b.probesOK++
+			if b.probesOK >= b.config.HalfOpenProbes {
+				b.setState(CIRCUIT_CLOSED, now)
+			}
+		}
+	}
+}
+
+// advance moves an open breaker to half-open once OpenTimeout has elapsed. The caller holds mu.
+func (b *CircuitBreaker) advance(now time.Time) {
+	if b.state == CIRCUIT_OPEN && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.setState(CIRCUIT_HALF_OPEN, now)
+	}
+}
+
+// setState changes the state, resets the counters and queues the change for OnStateChange. The caller holds mu.
+func (b *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	from := b.state
+	b.state = state
+	b.windowStart = now
+	b.requests, b.failures = 0, 0
+	b.probing, b.probesOK = 0, 0
+	if state == CIRCUIT_OPEN {
+		b.openedAt = now
+	}
+
+	b.changes = append(b.changes, circuitChange{from: from, to: state})
+}
+
+// unlock releases mu, then reports the pending state changes so that
+// OnStateChange may safely call back into the breaker.
+func (b *CircuitBreaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	if b.config.OnStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.config.OnStateChange(change.from, change.to)
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/x/ratelimit"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	var changes []string
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+		OpenTimeout:  10 * time.Second,
+		OnStateChange: func(from, to CircuitState) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+		now: func() time.Time { return now },
+	})
+
+	// Responses are served in order: 2 successes, a 5xx, a network error, an UNKNOWN_ERROR, ...
+	responses := []func() (*http.Response, error){
+		okResponse(`{"error":0}`),
+		okResponse(`{"error":-124}`), // an invalid token is a client error, not an outage
+		statusResponse(http.StatusBadGateway),
+		func() (*http.Response, error) { return nil, errors.New("connection reset") },
+		okResponse(`{"error":-100}`),
+		okResponse(`{"error":0}`),
+	}
+	calls := 0
+	zc := NewZaloClient("app-id", "secret", "verifier")
+	zc.UseHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		respond := responses[calls]
+		calls++
+		return respond()
+	})})
+	zc.UseCircuitBreaker(breaker)
+	zc.recordTemplate("", false) // Known not to be an E2EE template, so only sends are made
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		_, _ = zc.SendZnsMessage(ctx, ZnsSendMsgRequest{Phone: "84987654321"})
+	}
+	assert.Equal(t, CIRCUIT_OPEN, breaker.State(), "2 failures out of 4 requests trip the breaker")
+
+	_, err := zc.SendZnsMessage(ctx, ZnsSendMsgRequest{Phone: "84987654321"})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	var openErr *CircuitOpenError
+	require.True(t, errors.As(err, &openErr))
+	assert.Equal(t, 10*time.Second, openErr.RetryAfter)
+	assert.Equal(t, 4, calls, "no request is sent while open")
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, CIRCUIT_HALF_OPEN, breaker.State())
+	_, err = zc.SendZnsMessage(ctx, ZnsSendMsgRequest{Phone: "84987654321"})
+	require.NoError(t, err)
+	assert.Equal(t, CIRCUIT_OPEN, breaker.State(), "a failed probe opens the breaker again")
+
+	now = now.Add(10 * time.Second)
+	_, err = zc.GetZnsTemplateList(ctx, ZnsTplListRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, CIRCUIT_CLOSED, breaker.State(), "a successful probe closes the breaker")
+
+	assert.Equal(t, []string{
+		"CLOSED->OPEN",
+		"OPEN->HALF_OPEN",
+		"HALF_OPEN->OPEN",
+		"OPEN->HALF_OPEN",
+		"HALF_OPEN->CLOSED",
+	}, changes)
+}
+
+func TestCircuitBreakerHalfOpenLimitsProbes(t *testing.T) {
+	now := time.Unix(0, 0)
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		MinRequests:    1,
+		HalfOpenProbes: 2,
+		now:            func() time.Time { return now },
+	})
+
+	require.NoError(t, breaker.allow())
+	breaker.record(circuitFailure)
+	assert.Equal(t, CIRCUIT_OPEN, breaker.State())
+
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.allow())
+	require.NoError(t, breaker.allow())
+	assert.ErrorIs(t, breaker.allow(), ErrCircuitOpen, "only HalfOpenProbes requests are let through")
+
+	breaker.record(circuitSuccess)
+	assert.Equal(t, CIRCUIT_HALF_OPEN, breaker.State())
+	breaker.record(circuitSuccess)
+	assert.Equal(t, CIRCUIT_CLOSED, breaker.State())
+}
+
+func TestCircuitBreakerOpenSkipsRateLimiter(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{MinRequests: 1, OpenTimeout: time.Hour})
+	limiter, err := ratelimit.NewTokenBucket(0.001, 1)
+	require.NoError(t, err)
+
+	calls := 0
+	zc := NewZaloClient("app-id", "secret", "verifier")
+	zc.UseHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return statusResponse(http.StatusBadGateway)()
+	})})
+	zc.UseCircuitBreaker(breaker)
+	zc.UseRateLimiter(ENDPOINT_GROUP_TEMPLATE, limiter)
+
+	ctx := context.Background()
+	_, _ = zc.GetZnsTemplateList(ctx, ZnsTplListRequest{})
+	require.Equal(t, CIRCUIT_OPEN, breaker.State())
+
+	// The only token is used by the first request: an open breaker must fail before waiting for the next one
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = zc.SendZnsMessage(ctx, ZnsSendMsgRequest{Phone: "84987654321"})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	_, err = zc.GetZnsTemplateList(ctx, ZnsTplListRequest{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 1, calls)
+}
+
+func okResponse(body string) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+}
+
+func statusResponse(status int) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	}
+}
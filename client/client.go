@@ -1,20 +1,52 @@
 package client
 
 import (
+	"context"
+	"crypto/rsa"
+	"io"
 	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/ducminhgd/zalo-go-sdk/x/pkce"
 )
 
 type ZaloClient struct {
-	httpClient    *http.Client
-	logger        *slog.Logger
-	token         AccessToken
-	appID         string
-	secretKey     string
-	codeVerifier  string
-	codeChallenge string
+	httpClient     *http.Client
+	logger         *slog.Logger
+	appID          string
+	secretKey      string
+	codeVerifier   string
+	codeChallenge  string
+	businessURL    string
+	oauthURL       string
+	debugWriter    io.Writer
+	rateLimiters   map[EndpointGroup]RateLimiter
+	circuitBreaker *CircuitBreaker
+	quotaTracker   *QuotaTracker
+	spendTracker   *SpendTracker
+	appSecretProof bool
+	rawPhone       bool // phone numbers are sent without normalization
+	devMode        bool
+
+	tokenMu sync.RWMutex // guards token, which is refreshed while requests are sent
+	token   AccessToken
+
+	e2eeMu        sync.Mutex
+	e2eeTemplates map[string]bool // whether templates are E2EE templates, by template ID
+	rsaKey        *rsa.PublicKey  // RSA public key of the OA
+
+	idempotencyStore IdempotencyStore
+	inflightMu       sync.Mutex
+	inflight         map[string]*inflightSend
+
+	eventsMu                sync.Mutex
+	refreshExpiringWindow   time.Duration
+	onRefreshExpiring       func(ctx context.Context, token AccessToken)
+	onReauthRequired        func(ctx context.Context, err error)
+	refreshExpiringNotified string // refresh token the expiring callback was called for
+	reauthNotified          string // refresh token the reauthorization callback was called for
 }
 
 func NewZaloClient(appID, secretKey, codeVerifier string) *ZaloClient {
@@ -48,11 +80,22 @@ func (z *ZaloClient) GetLogger() *slog.Logger {
 	return z.logger
 }
 
+// SetAccessToken sets the access token used by authenticated requests.
+//
+// If the token carries ExpiresIn but no ExpiresAt, e.g. a token stored before
+// expiry metadata existed, it is assumed to have been obtained now.
 func (z *ZaloClient) SetAccessToken(token AccessToken) {
+	if token.AccessToken != "" {
+		token = token.withExpiry(time.Now())
+	}
+	z.tokenMu.Lock()
+	defer z.tokenMu.Unlock()
 	z.token = token
 }
 
 func (z *ZaloClient) GetAccessToken() AccessToken {
+	z.tokenMu.RLock()
+	defer z.tokenMu.RUnlock()
 	return z.token
 }
 
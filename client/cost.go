@@ -0,0 +1,283 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidPrice is returned when the price of a template cannot be parsed.
+	ErrInvalidPrice = errors.New("zalo: invalid template price")
+	// ErrBudgetExceeded is returned by the send methods when a message would exceed a budget of the spend tracker.
+	ErrBudgetExceeded = errors.New("zalo: ZNS budget exceeded")
+)
+
+// Price is an amount in VND.
+type Price int64
+
+// ParsePrice parses the price of a template detail, e.g. "300" or "300.0", rounded to the nearest VND.
+func ParsePrice(s string) (Price, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidPrice, s)
+	}
+	return Price(math.Round(f)), nil
+}
+
+// ParsePrice returns the parsed price of the template.
+func (d ZnsTplDetailData) ParsePrice() (Price, error) {
+	return ParsePrice(d.Price)
+}
+
+// TemplateCost is the cost of the messages of a template.
+type TemplateCost struct {
+	Count int
+	Price Price // Price of one message
+	Total Price
+}
+
+// CostEstimate is the expected cost of messages, in VND.
+type CostEstimate struct {
+	Templates map[string]TemplateCost // Costs by template ID
+	Total     Price
+}
+
+// PriceList is the price of the messages of templates, by template ID.
+type PriceList map[string]Price
+
+// Estimate returns the cost of a number of messages per template ID.
+// It fails if the price of a template is not in the list.
+func (p PriceList) Estimate(counts map[string]int) (CostEstimate, error) {
+	estimate := CostEstimate{Templates: make(map[string]TemplateCost, len(counts))}
+	for templateID, count := range counts {
+		price, ok := p[templateID]
+		if !ok {
+			return CostEstimate{}, fmt.Errorf("zalo: no price for template %s", templateID)
+		}
+		cost := TemplateCost{Count: count, Price: price, Total: price * Price(count)}
+		estimate.Templates[templateID] = cost
+		estimate.Total += cost.Total
+	}
+	return estimate, nil
+}
+
+// EstimateRequests returns the cost of sending requests.
+func (p PriceList) EstimateRequests(requests []ZnsSendMsgRequest) (CostEstimate, error) {
+	return p.Estimate(CountTemplates(requests))
+}
+
+// CountTemplates returns the number of requests per template ID.
+func CountTemplates(requests []ZnsSendMsgRequest) map[string]int {
+	counts := make(map[string]int)
+	for _, request := range requests {
+		counts[request.TemplateID]++
+	}
+	return counts
+}
+
+// GetPriceList gets the price of templates from their detail.
+// A template detail error is returned as a *ZnsError.
+func (z *ZaloClient) GetPriceList(ctx context.Context, templateIDs ...string) (PriceList, error) {
+	prices := make(PriceList, len(templateIDs))
+	for _, templateID := range templateIDs {
+		if _, ok := prices[templateID]; ok {
+			continue
+		}
+		price, err := z.templatePrice(ctx, templateID)
+		if err != nil {
+			return nil, err
+		}
+		prices[templateID] = price
+	}
+	return prices, nil
+}
+
+// EstimateCost returns the cost of a number of messages per template ID, with the prices of GetPriceList.
+func (z *ZaloClient) EstimateCost(ctx context.Context, counts map[string]int) (CostEstimate, error) {
+	templateIDs := make([]string, 0, len(counts))
+	for templateID := range counts {
+		templateIDs = append(templateIDs, templateID)
+	}
+	prices, err := z.GetPriceList(ctx, templateIDs...)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	return prices.Estimate(counts)
+}
+
+// EstimateRequestsCost returns the cost of sending requests, with the prices of GetPriceList.
+func (z *ZaloClient) EstimateRequestsCost(ctx context.Context, requests []ZnsSendMsgRequest) (CostEstimate, error) {
+	return z.EstimateCost(ctx, CountTemplates(requests))
+}
+
+// templatePrice returns the price of a template, from the spend tracker if it knows it,
+// or from the template detail.
+func (z *ZaloClient) templatePrice(ctx context.Context, templateID string) (Price, error) {
+	if tracker := z.GetSpendTracker(); tracker != nil {
+		if price, ok := tracker.Price(templateID); ok {
+			return price, nil
+		}
+	}
+	detail, err := z.GetZnsTemplateDetail(ctx, templateID)
+	if err != nil {
+		return 0, err
+	}
+	if detail.Error != SUCCESS {
+		return 0, &ZnsError{Code: detail.Error, Message: detail.Message}
+	}
+	return detail.Data.ParsePrice()
+}
+
+// Budget caps the spending of ZNS messages, in VND. A zero cap is unlimited.
+type Budget struct {
+	Daily   Price // Reset at midnight Vietnam time
+	Monthly Price // Reset on the first day of the month, Vietnam time
+}
+
+// SpendTracker tracks the spending of ZNS messages sent successfully, and blocks sends
+// that would exceed its budget once set with UseSpendTracker.
+//
+// Prices are learned from the template details got by the client, or set with SetPrice.
+// Messages sent in development mode are free and not counted.
+// A SpendTracker is safe for concurrent use.
+type SpendTracker struct {
+	mu       sync.Mutex
+	budget   Budget
+	prices   map[string]Price
+	day      string // Vietnam date of the daily spending, 2006-01-02
+	month    string // Vietnam month of the monthly spending, 2006-01
+	daily    Price
+	monthly  Price
+	reserved Price // Price of the messages being sent
+	now      func() time.Time
+}
+
+// NewSpendTracker creates a tracker with a budget and nothing spent.
+func NewSpendTracker(budget Budget) *SpendTracker {
+	return &SpendTracker{budget: budget, prices: make(map[string]Price), now: time.Now}
+}
+
+// SetBudget replaces the budget.
+func (s *SpendTracker) SetBudget(budget Budget) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.budget = budget
+}
+
+// SetPrice sets the price of the messages of a template.
+func (s *SpendTracker) SetPrice(templateID string, price Price) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices[templateID] = price
+}
+
+// Price returns the price of the messages of a template, and whether it is known.
+func (s *SpendTracker) Price(templateID string) (Price, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	price, ok := s.prices[templateID]
+	return price, ok
+}
+
+// Spent returns the amounts spent today and this month.
+func (s *SpendTracker) Spent() (daily, monthly Price) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roll()
+	return s.daily, s.monthly
+}
+
+// Allow returns an error wrapping ErrBudgetExceeded if a message of a template would exceed the budget.
+// The price of the template must be known.
+func (s *SpendTracker) Allow(templateID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	price, ok := s.prices[templateID]
+	if !ok {
+		return fmt.Errorf("zalo: no price for template %s", templateID)
+	}
+	return s.allow(price)
+}
+
+// Record adds the price of a message to the spending if the response is successful.
+// The price of the template must be known, see SetPrice.
+func (s *SpendTracker) Record(templateID string, response ZnsSendMsgReponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if price, ok := s.prices[templateID]; ok && response.Error == SUCCESS {
+		s.spend(price)
+	}
+}
+
+// reserve reserves the price of a message until it is settled, so that concurrent sends
+// cannot exceed the budget together.
+func (s *SpendTracker) reserve(price Price) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.allow(price); err != nil {
+		return err
+	}
+	s.reserved += price
+	return nil
+}
+
+// settle releases a reservation, spending it if the message was sent.
+func (s *SpendTracker) settle(price Price, sent bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reserved -= price
+	if sent {
+		s.spend(price)
+	}
+}
+
+// allow checks a price against the budget. The caller holds mu.
+func (s *SpendTracker) allow(price Price) error {
+	s.roll()
+	if s.budget.Daily > 0 && s.daily+s.reserved+price > s.budget.Daily {
+		return fmt.Errorf("%w: daily budget of %d VND, %d VND spent", ErrBudgetExceeded, s.budget.Daily, s.daily)
+	}
+	if s.budget.Monthly > 0 && s.monthly+s.reserved+price > s.budget.Monthly {
+		return fmt.Errorf("%w: monthly budget of %d VND, %d VND spent", ErrBudgetExceeded, s.budget.Monthly, s.monthly)
+	}
+	return nil
+}
+
+// spend adds a price to the spending. The caller holds mu.
+func (s *SpendTracker) spend(price Price) {
+	s.roll()
+	s.daily += price
+	s.monthly += price
+}
+
+// roll resets the spending of a past day or month. The caller holds mu.
+func (s *SpendTracker) roll() {
+	now := s.now().In(VietnamLocation)
+	if day := now.Format("2006-01-02"); day != s.day {
+		s.day, s.daily = day, 0
+	}
+	if month := now.Format("2006-01"); month != s.month {
+		s.month, s.monthly = month, 0
+	}
+}
+
+// UseSpendTracker sets the tracker of the spending of sent messages. Sends that would exceed its
+// budget are refused with ErrBudgetExceeded. Passing nil removes it.
+func (z *ZaloClient) UseSpendTracker(tracker *SpendTracker) {
+	z.spendTracker = tracker
+}
+
+// GetSpendTracker returns the spend tracker of the client, or nil if there is none.
+func (z *ZaloClient) GetSpendTracker() *SpendTracker {
+	return z.spendTracker
+}
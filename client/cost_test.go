@@ -0,0 +1,165 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		price string
+		want  client.Price
+		ok    bool
+	}{
+		{"300", 300, true},
+		{"300.0", 300, true},
+		{" 165.5 ", 166, true},
+		{"", 0, false},
+		{"free", 0, false},
+		{"-200", 0, false},
+	}
+	for _, tt := range tests {
+		price, err := client.ParsePrice(tt.price)
+		if !tt.ok {
+			assert.ErrorIs(t, err, client.ErrInvalidPrice, tt.price)
+			continue
+		}
+		require.NoError(t, err, tt.price)
+		assert.Equal(t, tt.want, price, tt.price)
+	}
+}
+
+func newPricedTestServer() *zalotest.Server {
+	srv := newSendTestServer()
+	srv.AddTemplate(client.ZnsTplDetailData{
+		TemplateID: 100,
+		Status:     client.ZNS_TPL_STATUS_ENABLED_NAME,
+		ListParams: []client.ZnsTplDetailParam{{Name: "cost", Require: true, Type: "NUMBER", MaxLength: 20}},
+		Price:      "300.0",
+	})
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 200, Status: client.ZNS_TPL_STATUS_ENABLED_NAME, Price: "440"})
+	return srv
+}
+
+func TestEstimateCost(t *testing.T) {
+	srv := newPricedTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+	ctx := context.Background()
+
+	estimate, err := zc.EstimateRequestsCost(ctx, []client.ZnsSendMsgRequest{
+		{TemplateID: "100"}, {TemplateID: "200"}, {TemplateID: "100"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, client.CostEstimate{
+		Templates: map[string]client.TemplateCost{
+			"100": {Count: 2, Price: 300, Total: 600},
+			"200": {Count: 1, Price: 440, Total: 440},
+		},
+		Total: 1040,
+	}, estimate)
+
+	_, err = zc.EstimateCost(ctx, map[string]int{"100": 1, "999": 1})
+	assert.ErrorIs(t, err, &client.ZnsError{Code: client.TEMPLATE_ID_INVALID})
+
+	_, err = client.PriceList{"100": 300}.Estimate(map[string]int{"200": 1})
+	assert.Error(t, err)
+}
+
+func TestSpendTracker(t *testing.T) {
+	srv := newPricedTestServer()
+	defer srv.Close()
+	srv.AddAdmin("84987654321")
+	zc := srv.Client()
+	tracker := client.NewSpendTracker(client.Budget{Daily: 1000})
+	zc.UseSpendTracker(tracker)
+	ctx := context.Background()
+
+	send := func(mode client.SendMode) (client.ZnsSendMsgReponse, error) {
+		return zc.SendZnsMessage(ctx, client.ZnsSendMsgRequest{
+			Phone:        "84987654321",
+			TemplateID:   "100",
+			TemplateData: map[string]string{"cost": "1"},
+			Mode:         mode,
+		})
+	}
+
+	for i := 0; i < 3; i++ {
+		response, err := send(client.SEND_MODE_DEFAULT)
+		require.NoError(t, err)
+		require.Equal(t, client.SUCCESS, response.Error)
+	}
+	daily, monthly := tracker.Spent()
+	assert.Equal(t, client.Price(900), daily)
+	assert.Equal(t, client.Price(900), monthly)
+	price, ok := tracker.Price("100")
+	assert.True(t, ok)
+	assert.Equal(t, client.Price(300), price)
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_TEMPLATE_DETAIL), 1, "the price is fetched once")
+
+	_, err := send(client.SEND_MODE_DEFAULT)
+	assert.ErrorIs(t, err, client.ErrBudgetExceeded)
+	assert.ErrorIs(t, tracker.Allow("100"), client.ErrBudgetExceeded)
+	assert.Len(t, srv.Messages(), 3)
+
+	// Development messages are free
+	response, err := send(client.SEND_MODE_DEVELOPMENT)
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+
+	// A failed send is not spent
+	tracker.SetBudget(client.Budget{Monthly: 1500})
+	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.OUT_OF_QUOTA)
+	response, err = send(client.SEND_MODE_DEFAULT)
+	require.NoError(t, err)
+	assert.Equal(t, client.OUT_OF_QUOTA, response.Error)
+	daily, _ = tracker.Spent()
+	assert.Equal(t, client.Price(900), daily)
+	assert.NoError(t, tracker.Allow("100"))
+}
+
+func TestSpendTrackerPriceError(t *testing.T) {
+	srv := newPricedTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+	ctx := context.Background()
+	request := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "100", TemplateData: map[string]string{"cost": "1"}}
+
+	// The template is known to the client, but its price is not known to the tracker
+	_, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	zc.UseSpendTracker(client.NewSpendTracker(client.Budget{Daily: 1000}))
+
+	srv.InjectError(client.ENDPOINT_TEMPLATE_DETAIL, client.UNKNOWN_ERROR)
+	response, err := zc.SendZnsMessage(ctx, request)
+	assert.ErrorIs(t, err, &client.ZnsError{Code: client.UNKNOWN_ERROR}, "the detail failure is an error, not a send response")
+	assert.ErrorContains(t, err, "getting price of template 100")
+	assert.Zero(t, response)
+	assert.Len(t, srv.Messages(), 1)
+}
+
+func TestSendBulkStopsWhenBudgetExceeded(t *testing.T) {
+	srv := newPricedTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+	tracker := client.NewSpendTracker(client.Budget{Daily: 1000})
+	tracker.SetPrice("100", 300)
+	zc.UseSpendTracker(tracker)
+
+	summary, err := zc.SendBulk(context.Background(), bulkRequests(10), client.BulkOptions{Workers: 1})
+	var stopErr *client.BulkStoppedError
+	require.True(t, errors.As(err, &stopErr))
+	assert.ErrorIs(t, err, client.ErrBudgetExceeded)
+	assert.Equal(t, 3, summary.Sent)
+	assert.Equal(t, 7, summary.Skipped)
+	assert.Equal(t, 0, summary.Failed)
+	assert.Len(t, srv.Messages(), 3)
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_TEMPLATE_DETAIL), 1, "the detail is fetched once to check for E2EE, not for the known price")
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DEBUG_REDACTED replaces the value of sensitive headers in debug dumps.
+const DEBUG_REDACTED = "[REDACTED]"
+
+// debugRedactedHeaders lists the headers whose values are never written to a debug dump.
+var debugRedactedHeaders = map[string]bool{
+	http.CanonicalHeaderKey("secret_key"):      true,
+	http.CanonicalHeaderKey("access_token"):    true,
+	http.CanonicalHeaderKey("appsecret_proof"): true,
+}
+
+// debugRedactedFields lists the form fields and JSON fields of access token requests and responses
+// whose values are never written to a debug dump.
+var debugRedactedFields = []string{"access_token", "refresh_token", "code", "code_verifier", "secret_key"}
+
+// UseDebugWriter enables debug dumping of every HTTP exchange with the Zalo API.
+//
+// When enabled, the client writes the method, URL, headers and body of each request,
+// and the status, headers, body and timing of each response to w.
+// The values of the secret_key, access_token and appsecret_proof headers are redacted, and so are
+// the tokens, authorization code and code verifier in the bodies of access token requests and responses.
+// Passing nil disables debug dumping.
+func (z *ZaloClient) UseDebugWriter(w io.Writer) {
+	z.debugWriter = w
+}
+
+// snapshotRequestBody returns a copy of the request body, leaving the request readable.
+func (z *ZaloClient) snapshotRequestBody(ctx context.Context, req *http.Request) []byte {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error reading request body for debug dump:", slog.Any("err", err))
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body
+}
+
+// dumpExchange writes a request and its response (or transport error) to the debug writer.
+// The response body is read and replaced so that callers can still consume it.
+func (z *ZaloClient) dumpExchange(ctx context.Context, req *http.Request, reqBody []byte, resp *http.Response, respErr error, elapsed time.Duration) {
+	var buf bytes.Buffer
+	// The OAuth base URL set with UseBaseURLs may have a path, e.g. behind a proxy
+	tokenExchange := strings.HasSuffix(req.URL.Path, strings.TrimPrefix(ENDPOINT_GET_ACCESS_TOKEN, BASE_URL_OAUTH))
+	if tokenExchange {
+		reqBody = redactDebugBody(reqBody)
+	}
+
+	fmt.Fprintf(&buf, "--> %s %s\n", req.Method, req.URL.String())
+	writeDebugHeaders(&buf, req.Header)
+	if len(reqBody) > 0 {
+		fmt.Fprintf(&buf, "\n%s\n", reqBody)
+	}
+
+	if respErr != nil {
+		fmt.Fprintf(&buf, "<-- error: %v (%s)\n\n", respErr, elapsed)
+	} else {
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			z.GetLogger().ErrorContext(ctx, "Error reading response body for debug dump:", slog.Any("err", err))
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		if tokenExchange {
+			respBody = redactDebugBody(respBody)
+		}
+		fmt.Fprintf(&buf, "<-- %s (%s)\n", resp.Status, elapsed)
+		writeDebugHeaders(&buf, resp.Header)
+		if len(respBody) > 0 {
+			fmt.Fprintf(&buf, "\n%s\n", respBody)
+		}
+		buf.WriteString("\n")
+	}
+
+	if _, err := z.debugWriter.Write(buf.Bytes()); err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error writing debug dump:", slog.Any("err", err))
+	}
+}
+
+// writeDebugHeaders writes headers sorted by name, redacting sensitive values.
+func writeDebugHeaders(buf *bytes.Buffer, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if debugRedactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = DEBUG_REDACTED
+		}
+		fmt.Fprintf(buf, "%s: %s\n", name, value)
+	}
+}
+
+// redactDebugBody redacts the sensitive fields of a form or JSON body.
+// A body that cannot be parsed is redacted entirely.
+func redactDebugBody(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return []byte(DEBUG_REDACTED)
+		}
+		for _, name := range debugRedactedFields {
+			if _, ok := fields[name]; ok {
+				fields[name] = json.RawMessage(strconv.Quote(DEBUG_REDACTED))
+			}
+		}
+		redacted, err := json.Marshal(fields)
+		if err != nil {
+			return []byte(DEBUG_REDACTED)
+		}
+		return redacted
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return []byte(DEBUG_REDACTED)
+	}
+	for _, name := range debugRedactedFields {
+		if form.Has(name) {
+			form.Set(name, DEBUG_REDACTED)
+		}
+	}
+	// Keep the placeholder readable
+	return []byte(strings.ReplaceAll(form.Encode(), url.QueryEscape(DEBUG_REDACTED), DEBUG_REDACTED))
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestUseDebugWriter(t *testing.T) {
+	var sentBody string
+	zc := NewZaloClient("app-id", "super-secret", "verifier")
+	zc.SetAccessToken(AccessToken{AccessToken: "super-token"})
+	zc.UseHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		sentBody = string(body)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"error":-122,"message":"Body format is invalid"}`)),
+		}, nil
+	})})
+
+	zc.recordTemplate("1234", false) // Known not to be an E2EE template, so only the send is dumped
+
+	var dump bytes.Buffer
+	zc.UseDebugWriter(&dump)
+
+	response, err := zc.SendZnsMessage(context.Background(), ZnsSendMsgRequest{
+		Phone:      "84987654321",
+		TemplateID: "1234",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, BODY_FORMAT_INVALID, response.Error)
+	assert.Contains(t, sentBody, `"template_id":"1234"`, "request body must still reach the transport")
+
+	out := dump.String()
+	assert.Contains(t, out, "--> POST "+ENDPOINT_MESSAGE_SEND)
+	assert.Contains(t, out, `"template_id":"1234"`)
+	assert.Contains(t, out, "Access_token: "+DEBUG_REDACTED)
+	assert.Contains(t, out, "<-- 200 OK")
+	assert.Contains(t, out, `"error":-122`)
+	assert.NotContains(t, out, "super-token")
+}
+
+func TestUseDebugWriterRedactsSecretKey(t *testing.T) {
+	zc := NewZaloClient("app-id", "super-secret", "verifier")
+	zc.UseHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, io.ErrUnexpectedEOF
+	})})
+
+	var dump bytes.Buffer
+	zc.UseDebugWriter(&dump)
+
+	_, err := zc.RefreshAccessToken(context.Background(), AccessTokenRequest{RefreshToken: "refresh"})
+	require.Error(t, err)
+
+	out := dump.String()
+	assert.Contains(t, out, "Secret_key: "+DEBUG_REDACTED)
+	assert.Contains(t, out, "<-- error:")
+	assert.NotContains(t, out, "super-secret")
+}
+
+func TestUseDebugWriterRedactsTokenBodies(t *testing.T) {
+	// The OAuth base URL may have a path, e.g. behind a proxy
+	for _, oauthURL := range []string{"", "https://gateway.example.com/zalo/oauth"} {
+		zc := NewZaloClient("app-id", "super-secret", "super-verifier")
+		if oauthURL != "" {
+			zc.UseBaseURLs("https://gateway.example.com/zalo/business", oauthURL)
+		}
+		zc.UseHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"access_token":"new-access","refresh_token":"new-refresh","expires_in":"90000"}`)),
+			}, nil
+		})})
+
+		var dump bytes.Buffer
+		zc.UseDebugWriter(&dump)
+
+		token, err := zc.RefreshAccessToken(context.Background(), AccessTokenRequest{RefreshToken: "old-refresh"})
+		require.NoError(t, err, oauthURL)
+		assert.Equal(t, "new-access", token.AccessToken, "the response must still reach the caller")
+
+		out := dump.String()
+		assert.Contains(t, out, "refresh_token="+DEBUG_REDACTED, oauthURL)
+		assert.Contains(t, out, `"access_token":"`+DEBUG_REDACTED+`"`, oauthURL)
+		assert.Contains(t, out, `"expires_in":"90000"`, oauthURL)
+		for _, secret := range []string{"old-refresh", "new-access", "new-refresh", "super-verifier"} {
+			assert.NotContains(t, out, secret, oauthURL)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrE2EETemplate is returned by SendZnsMessage for an E2EE template, which must not be sent in plaintext.
+	ErrE2EETemplate = errors.New("zalo: E2EE template must be sent with SendZnsE2EEMessage")
+	// ErrInvalidRsaKey is returned when the RSA public key of the OA cannot be parsed.
+	ErrInvalidRsaKey = errors.New("zalo: invalid RSA public key")
+)
+
+type RsaKeyData struct {
+	Key string `json:"key"` // The RSA public key, as PEM or base64 encoded DER
+}
+
+type RsaKeyResponse struct {
+	Error   int        `json:"error"`
+	Message string     `json:"message"`
+	Data    RsaKeyData `json:"data"`
+}
+
+// GenerateRsaKey generates the RSA key pair of the OA, used to encrypt the template data of E2EE templates.
+// It returns RSA_KEY_EXISTED if the OA already has one.
+func (z *ZaloClient) GenerateRsaKey(ctx context.Context) (RsaKeyResponse, error) {
+	return z.requestRsaKey(ctx, "POST", ENDPOINT_RSA_KEY_GEN)
+}
+
+// GetRsaKey gets the RSA public key of the OA.
+// It returns RSA_KEY_NOT_EXISTED if the key was not generated with GenerateRsaKey.
+func (z *ZaloClient) GetRsaKey(ctx context.Context) (RsaKeyResponse, error) {
+	return z.requestRsaKey(ctx, "GET", ENDPOINT_RSA_KEY_GET)
+}
+
+func (z *ZaloClient) requestRsaKey(ctx context.Context, method, endpoint string) (RsaKeyResponse, error) {
+	var response RsaKeyResponse
+
+	req, err := http.NewRequest(method, z.endpoint(endpoint), nil)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error creating request:", slog.Any("err", err))
+		return response, err
+	}
+
+	// Set headers
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("access_token", z.GetAccessToken().AccessToken)
+	resp, err := z.do(ctx, ENDPOINT_GROUP_TEMPLATE, req)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error sending request:", slog.Any("err", err))
+		return response, err
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error reading response:", slog.Any("err", err))
+		return response, err
+	}
+
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error unmarshalling response:", slog.Any("err", err))
+		return response, err
+	}
+
+	return response, nil
+}
+
+// ParseRsaPublicKey parses an RSA public key returned by GetRsaKey.
+// PEM and base64 encoded DER keys in the PKIX or PKCS #1 form are accepted.
+func ParseRsaPublicKey(key string) (*rsa.PublicKey, error) {
+	var der []byte
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+		if err != nil {
+			return nil, ErrInvalidRsaKey
+		}
+		der = decoded
+	}
+
+	if public, err := x509.ParsePKIXPublicKey(der); err == nil {
+		if rsaKey, ok := public.(*rsa.PublicKey); ok {
+			return rsaKey, nil
+		}
+		return nil, ErrInvalidRsaKey
+	}
+	if rsaKey, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return rsaKey, nil
+	}
+	return nil, ErrInvalidRsaKey
+}
+
+// EncryptTemplateData encrypts every value of template data with an RSA public key,
+// using PKCS #1 v1.5 padding, and returns the base64 encoded ciphertexts.
+// Parameter names are left in clear so that Zalo can match them with the template.
+func EncryptTemplateData(key *rsa.PublicKey, data map[string]string) (map[string]string, error) {
+	encrypted := make(map[string]string, len(data))
+	for name, value := range data {
+		ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, key, []byte(value))
+		if err != nil {
+			return nil, err
+		}
+		encrypted[name] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+	return encrypted, nil
+}
+
+// SendZnsE2EEMessage sends a ZNS message of an end-to-end encrypted template, e.g. a banking OTP.
+//
+// The template detail is fetched to check that the template is an E2EE template; a regular template
+// gets a ZNS_NOT_AN_E2EE_TEMPLATE response without being sent. The template data is then encrypted
+// with the RSA public key of the OA, see GetRsaKey, and sent to the RSA endpoint. Template details
+// and the key are cached by the client; the key is fetched again when Zalo cannot decrypt a message.
+//
+// The first send of a template unknown to the client makes an extra template detail request.
+// If it fails, the message is not sent and an error wrapping the failure, e.g. a *ZnsError, is returned.
+// Errors getting the key are reported in the response like send errors.
+// Otherwise it behaves like SendZnsMessage.
+func (z *ZaloClient) SendZnsE2EEMessage(ctx context.Context, request ZnsSendMsgRequest) (ZnsSendMsgReponse, error) {
+	e2ee, err := z.lookupE2EETemplate(ctx, request.TemplateID)
+	if err != nil {
+		return ZnsSendMsgReponse{}, err
+	}
+	if !e2ee {
+		return ZnsSendMsgReponse{Error: ZNS_NOT_AN_E2EE_TEMPLATE, Message: "ZNS template is not an E2EE template"}, nil
+	}
+
+	key, response, err := z.rsaPublicKey(ctx)
+	if err != nil || response.Error != SUCCESS {
+		return response, err
+	}
+	request.TemplateData, err = EncryptTemplateData(key, request.TemplateData)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error encrypting template data:", slog.Any("err", err))
+		return ZnsSendMsgReponse{}, err
+	}
+
+	response, err = z.sendMessage(ctx, ENDPOINT_MESSAGE_SEND_RSA, request)
+	if err == nil && (response.Error == RSA_MESSAGE_DECODE_FAILED || response.Error == RSA_KEY_NOT_EXISTED) {
+		// The key was probably regenerated
+		z.e2eeMu.Lock()
+		z.rsaKey = nil
+		z.e2eeMu.Unlock()
+	}
+	return response, err
+}
+
+// recordTemplate remembers whether a template is an E2EE template.
+func (z *ZaloClient) recordTemplate(templateID string, e2ee bool) {
+	z.e2eeMu.Lock()
+	defer z.e2eeMu.Unlock()
+
+	if z.e2eeTemplates == nil {
+		z.e2eeTemplates = make(map[string]bool)
+	}
+	z.e2eeTemplates[templateID] = e2ee
+}
+
+// lookupE2EETemplate reports whether a template is an E2EE template, fetching its detail if unknown.
+// An error getting the detail is returned wrapped, e.g. a *ZnsError for an error response.
+func (z *ZaloClient) lookupE2EETemplate(ctx context.Context, templateID string) (bool, error) {
+	z.e2eeMu.Lock()
+	e2ee, ok := z.e2eeTemplates[templateID]
+	z.e2eeMu.Unlock()
+	if ok {
+		return e2ee, nil
+	}
+
+	detail, err := z.GetZnsTemplateDetail(ctx, templateID)
+	if err == nil && detail.Error != SUCCESS {
+		err = &ZnsError{Code: detail.Error, Message: detail.Message}
+	}
+	if err != nil {
+		return false, fmt.Errorf("zalo: getting detail of template %s: %w", templateID, err)
+	}
+	return detail.Data.E2EE, nil
+}
+
+// rsaPublicKey returns the RSA public key of the OA, fetching it if not cached.
+// A key error is returned as a send response.
+func (z *ZaloClient) rsaPublicKey(ctx context.Context) (*rsa.PublicKey, ZnsSendMsgReponse, error) {
+	z.e2eeMu.Lock()
+	key := z.rsaKey
+	z.e2eeMu.Unlock()
+	if key != nil {
+		return key, ZnsSendMsgReponse{}, nil
+	}
+
+	keyResponse, err := z.GetRsaKey(ctx)
+	if err != nil || keyResponse.Error != SUCCESS {
+		return nil, ZnsSendMsgReponse{Error: keyResponse.Error, Message: keyResponse.Message}, err
+	}
+	key, err = ParseRsaPublicKey(keyResponse.Data.Key)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error parsing RSA key:", slog.Any("err", err))
+		return nil, ZnsSendMsgReponse{}, err
+	}
+
+	z.e2eeMu.Lock()
+	z.rsaKey = key
+	z.e2eeMu.Unlock()
+	return key, ZnsSendMsgReponse{}, nil
+}
@@ -0,0 +1,118 @@
+package client_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+func TestSendZnsE2EEMessage(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	srv.AddTemplate(client.ZnsTplDetailData{
+		TemplateID: 200,
+		Status:     client.ZNS_TPL_STATUS_ENABLED_NAME,
+		ListParams: []client.ZnsTplDetailParam{{Name: "otp", Require: true, Type: "STRING", MaxLength: 8}},
+		E2EE:       true,
+	})
+
+	zc := srv.Client()
+	ctx := context.Background()
+	otp := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "200", TemplateData: map[string]string{"otp": "123456"}}
+
+	response, err := zc.SendZnsE2EEMessage(ctx, otp)
+	require.NoError(t, err)
+	assert.Equal(t, client.RSA_KEY_NOT_EXISTED, response.Error)
+
+	keyResponse, err := zc.GenerateRsaKey(ctx)
+	require.NoError(t, err)
+	require.Equal(t, client.SUCCESS, keyResponse.Error)
+
+	response, err = zc.SendZnsE2EEMessage(ctx, otp)
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+
+	requests := srv.RequestsTo(client.ENDPOINT_MESSAGE_SEND_RSA)
+	require.Len(t, requests, 1)
+	assert.NotContains(t, string(requests[0].Body), "123456", "template data is encrypted")
+	messages := srv.Messages()
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].Encrypted)
+	assert.Equal(t, map[string]string{"otp": "123456"}, messages[0].TemplateData)
+
+	_, err = zc.SendZnsMessage(ctx, otp)
+	assert.ErrorIs(t, err, client.ErrE2EETemplate, "E2EE templates are not sent in plaintext")
+	assert.Empty(t, srv.RequestsTo(client.ENDPOINT_MESSAGE_SEND))
+
+	regular := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "100", TemplateData: map[string]string{"cost": "1"}}
+	response, err = zc.SendZnsE2EEMessage(ctx, regular)
+	require.NoError(t, err)
+	assert.Equal(t, client.ZNS_NOT_AN_E2EE_TEMPLATE, response.Error)
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_MESSAGE_SEND_RSA), 1)
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_TEMPLATE_DETAIL), 2, "template details are cached")
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_RSA_KEY_GET), 2, "the key is cached once fetched")
+
+	unknown := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "404", TemplateData: map[string]string{"otp": "123456"}}
+	_, err = zc.SendZnsE2EEMessage(ctx, unknown)
+	assert.ErrorIs(t, err, &client.ZnsError{Code: client.TEMPLATE_ID_INVALID})
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_MESSAGE_SEND_RSA), 1)
+}
+
+func TestSendZnsMessageUnfetchedE2EETemplate(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	srv.AddTemplate(client.ZnsTplDetailData{
+		TemplateID: 200,
+		Status:     client.ZNS_TPL_STATUS_ENABLED_NAME,
+		ListParams: []client.ZnsTplDetailParam{{Name: "otp", Require: true, Type: "STRING", MaxLength: 8}},
+		E2EE:       true,
+	})
+
+	zc := srv.Client()
+	ctx := context.Background()
+	otp := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "200", TemplateData: map[string]string{"otp": "123456"}}
+
+	// The client never fetched the template: its detail is checked before sending in plaintext
+	srv.InjectError(client.ENDPOINT_TEMPLATE_DETAIL, client.UNKNOWN_ERROR)
+	_, err := zc.SendZnsMessage(ctx, otp)
+	assert.ErrorIs(t, err, &client.ZnsError{Code: client.UNKNOWN_ERROR}, "a failed lookup does not send")
+
+	_, err = zc.SendZnsMessage(ctx, otp)
+	assert.ErrorIs(t, err, client.ErrE2EETemplate)
+	assert.Empty(t, srv.RequestsTo(client.ENDPOINT_MESSAGE_SEND))
+	assert.Empty(t, srv.Messages())
+}
+
+func TestParseRsaPublicKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	pkcs1 := x509.MarshalPKCS1PublicKey(&key.PublicKey)
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"base64 PKIX", base64.StdEncoding.EncodeToString(pkix)},
+		{"base64 PKCS1", base64.StdEncoding.EncodeToString(pkcs1)},
+		{"PEM", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))},
+	}
+	for _, tt := range tests {
+		parsed, err := client.ParseRsaPublicKey(tt.input)
+		require.NoError(t, err, tt.name)
+		assert.True(t, key.PublicKey.Equal(parsed), tt.name)
+	}
+
+	_, err = client.ParseRsaPublicKey("not a key")
+	assert.ErrorIs(t, err, client.ErrInvalidRsaKey)
+}
@@ -1,17 +1,45 @@
 package client
 
+import "strings"
+
+const (
+	BASE_URL_BUSINESS = "https://business.openapi.zalo.me"
+	BASE_URL_OAUTH    = "https://oauth.zaloapp.com"
+)
+
 const (
-	ENDPOINT_MESSAGE_SEND            = "https://business.openapi.zalo.me/message/template"
-	ENDPOINT_MESSAGE_SEND_HASH_PHONE = "https://business.openapi.zalo.me/message/template/hashphone"
-	ENDPOINT_MESSAGE_SEND_RSA        = "https://business.openapi.zalo.me/rsa/message/template"
-	ENDPOINT_MESSAGE_INQUIRY_STATUS  = "https://business.openapi.zalo.me/message/status"
-	ENDPOINT_MESAGE_QUOTA            = "https://business.openapi.zalo.me/message/quota"
+	ENDPOINT_MESSAGE_SEND            = BASE_URL_BUSINESS + "/message/template"
+	ENDPOINT_MESSAGE_SEND_HASH_PHONE = BASE_URL_BUSINESS + "/message/template/hashphone"
+	ENDPOINT_MESSAGE_SEND_RSA        = BASE_URL_BUSINESS + "/rsa/message/template"
+	ENDPOINT_MESSAGE_INQUIRY_STATUS  = BASE_URL_BUSINESS + "/message/status"
+	ENDPOINT_MESAGE_QUOTA            = BASE_URL_BUSINESS + "/message/quota"
 
-	ENDPOINT_RSA_KEY_GEN = "https://business.openapi.zalo.me/rsa/key/gen"
-	ENDPOINT_RSA_KEY_GET = "https://business.openapi.zalo.me/rsa/key/get"
+	ENDPOINT_RSA_KEY_GEN = BASE_URL_BUSINESS + "/rsa/key/gen"
+	ENDPOINT_RSA_KEY_GET = BASE_URL_BUSINESS + "/rsa/key/get"
 
-	ENDPOINT_TEMPLATE_LIST   = "https://business.openapi.zalo.me/template/all"
-	ENDPOINT_TEMPLATE_DETAIL = "https://business.openapi.zalo.me/template/info/v2"
+	ENDPOINT_TEMPLATE_LIST   = BASE_URL_BUSINESS + "/template/all"
+	ENDPOINT_TEMPLATE_DETAIL = BASE_URL_BUSINESS + "/template/info/v2"
+	ENDPOINT_TEMPLATE_CREATE = BASE_URL_BUSINESS + "/template/create"
 
-	ENDPOINT_GET_ACCESS_TOKEN = "https://oauth.zaloapp.com/v4/oa/access_token"
+	ENDPOINT_GET_ACCESS_TOKEN = BASE_URL_OAUTH + "/v4/oa/access_token"
+	ENDPOINT_OA_PERMISSION    = BASE_URL_OAUTH + "/v4/oa/permission"
 )
+
+// UseBaseURLs overrides the base URLs the client sends requests to,
+// e.g. to target a fake server in tests. An empty string keeps the default.
+// businessURL replaces BASE_URL_BUSINESS and oauthURL replaces BASE_URL_OAUTH.
+func (z *ZaloClient) UseBaseURLs(businessURL, oauthURL string) {
+	z.businessURL = strings.TrimRight(businessURL, "/")
+	z.oauthURL = strings.TrimRight(oauthURL, "/")
+}
+
+// endpoint returns the URL of an ENDPOINT_* constant, honouring the base URLs set by UseBaseURLs.
+func (z *ZaloClient) endpoint(endpoint string) string {
+	if z.businessURL != "" && strings.HasPrefix(endpoint, BASE_URL_BUSINESS) {
+		return z.businessURL + strings.TrimPrefix(endpoint, BASE_URL_BUSINESS)
+	}
+	if z.oauthURL != "" && strings.HasPrefix(endpoint, BASE_URL_OAUTH) {
+		return z.oauthURL + strings.TrimPrefix(endpoint, BASE_URL_OAUTH)
+	}
+	return endpoint
+}
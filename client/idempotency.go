@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// NewTrackingID returns a random 32 characters tracking ID.
+func NewTrackingID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms
+	}
+	return hex.EncodeToString(b)
+}
+
+// IdempotencyStore remembers the responses of successful sends by tracking ID.
+// Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Get returns the response recorded for a tracking ID, and whether there is one.
+	Get(ctx context.Context, trackingID string) (ZnsSendMsgReponse, bool, error)
+	// Put records the response of a successful send.
+	Put(ctx context.Context, trackingID string, response ZnsSendMsgReponse) error
+}
+
+// UseIdempotencyStore sets the store used to deduplicate sends by tracking ID. Passing nil removes it.
+//
+// With a store, sending a tracking ID that was already sent successfully returns the original
+// response instead of sending again, and concurrent sends of the same tracking ID by the client
+// are sent once. Failed sends are not recorded, so they can be retried.
+func (z *ZaloClient) UseIdempotencyStore(store IdempotencyStore) {
+	z.idempotencyStore = store
+}
+
+// GetIdempotencyStore returns the idempotency store of the client, or nil if there is none.
+func (z *ZaloClient) GetIdempotencyStore() IdempotencyStore {
+	return z.idempotencyStore
+}
+
+// inflightSend is a send in progress, waited on by concurrent sends of the same tracking ID.
+type inflightSend struct {
+	done     chan struct{}
+	response ZnsSendMsgReponse
+	err      error
+}
+
+// sendIdempotent calls send unless the idempotency store has a response for trackingID.
+func (z *ZaloClient) sendIdempotent(ctx context.Context, trackingID string, send func() (ZnsSendMsgReponse, error)) (ZnsSendMsgReponse, error) {
+	store := z.GetIdempotencyStore()
+	if store == nil {
+		return send()
+	}
+
+	for {
+		z.inflightMu.Lock()
+		call, ok := z.inflight[trackingID]
+		if !ok {
+			break // keep the lock to register this send
+		}
+		z.inflightMu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ZnsSendMsgReponse{}, ctx.Err()
+		case <-call.done:
+		}
+		if call.err == nil && call.response.Error == SUCCESS {
+			return call.response, nil
+		}
+		// The concurrent send failed: try again
+	}
+
+	call := &inflightSend{done: make(chan struct{})}
+	if z.inflight == nil {
+		z.inflight = make(map[string]*inflightSend)
+	}
+	z.inflight[trackingID] = call
+	z.inflightMu.Unlock()
+
+	defer func() {
+		z.inflightMu.Lock()
+		delete(z.inflight, trackingID)
+		z.inflightMu.Unlock()
+		close(call.done)
+	}()
+
+	response, found, err := store.Get(ctx, trackingID)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error reading idempotency store:", slog.Any("err", err))
+		call.err = err
+		return response, err
+	}
+	if found {
+		call.response = response
+		return response, nil
+	}
+
+	call.response, call.err = send()
+	if call.err == nil && call.response.Error == SUCCESS {
+		if err := store.Put(ctx, trackingID, call.response); err != nil {
+			// The message was sent: report it, a retry would send it again
+			z.GetLogger().ErrorContext(ctx, "Error writing idempotency store:", slog.Any("err", err))
+		}
+	}
+	return call.response, call.err
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore keeping responses in memory for a limited time.
+type MemoryIdempotencyStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]idempotencyEntry
+	nextSweep time.Time
+}
+
+type idempotencyEntry struct {
+	response  ZnsSendMsgReponse
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates a store remembering responses for ttl.
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]idempotencyEntry),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Get(ctx context.Context, trackingID string) (ZnsSendMsgReponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[trackingID]
+	if !ok || !s.now().Before(entry.expiresAt) {
+		return ZnsSendMsgReponse{}, false, nil
+	}
+	return entry.response, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Put(ctx context.Context, trackingID string, response ZnsSendMsgReponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !now.Before(s.nextSweep) {
+		// Drop expired entries at most once per ttl
+		for id, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, id)
+			}
+		}
+		s.nextSweep = now.Add(s.ttl)
+	}
+	s.entries[trackingID] = idempotencyEntry{response: response, expiresAt: now.Add(s.ttl)}
+	return nil
+}
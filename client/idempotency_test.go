@@ -0,0 +1,92 @@
+package client_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+func TestSendZnsMessageGeneratesTrackingID(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+
+	request := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "100", TemplateData: map[string]string{"cost": "1"}}
+	for i := 0; i < 2; i++ {
+		_, err := zc.SendZnsMessage(context.Background(), request)
+		require.NoError(t, err)
+	}
+
+	messages := srv.Messages()
+	require.Len(t, messages, 2)
+	assert.Len(t, messages[0].TrackingID, 32)
+	assert.NotEqual(t, messages[0].TrackingID, messages[1].TrackingID)
+}
+
+func TestUseIdempotencyStore(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+	zc.UseIdempotencyStore(client.NewMemoryIdempotencyStore(time.Hour))
+
+	ctx := context.Background()
+	request := client.ZnsSendMsgRequest{
+		Phone:        "84987654321",
+		TemplateID:   "100",
+		TemplateData: map[string]string{"cost": "1"},
+		TrackingID:   "invoice-42",
+	}
+
+	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.UNKNOWN_ERROR)
+	failed, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.UNKNOWN_ERROR, failed.Error)
+
+	first, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, first.Error, "failed sends are not remembered")
+
+	second, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Len(t, srv.Messages(), 1)
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_MESSAGE_SEND), 2)
+}
+
+func TestUseIdempotencyStoreConcurrentSends(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	zc := srv.Client()
+	zc.UseIdempotencyStore(client.NewMemoryIdempotencyStore(time.Hour))
+
+	request := client.ZnsSendMsgRequest{
+		Phone:        "84987654321",
+		TemplateID:   "100",
+		TemplateData: map[string]string{"cost": "1"},
+		TrackingID:   "invoice-43",
+	}
+
+	var wg sync.WaitGroup
+	msgIDs := make([]string, 10)
+	for i := range msgIDs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := zc.SendZnsMessage(context.Background(), request)
+			assert.NoError(t, err)
+			msgIDs[i] = response.Data.MsgID
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, srv.Messages(), 1)
+	for _, msgID := range msgIDs {
+		assert.Equal(t, srv.Messages()[0].MsgID, msgID)
+	}
+}
@@ -0,0 +1,24 @@
+package client
+
+import "context"
+
+var (
+	ErrJourneyTokenMissing = &ZnsError{Code: ZNS_JOURNEY_TOKEN_MISSING, Message: "ZNS journey token is missing"}
+	ErrJourneyTokenInvalid = &ZnsError{Code: ZNS_JOURNEY_TOKEN_INVALID, Message: "ZNS journey token is invalid"}
+	ErrJourneyTokenExpired = &ZnsError{Code: ZNS_JOURNEY_TOKEN_EXPIRED, Message: "ZNS journey token is expired"}
+)
+
+// SendZnsJourneyMessage sends a ZNS message of a journey template, within the journey identified by journeyToken.
+// Every message of a journey, e.g. the order confirmation, shipping and delivery messages of an order,
+// is sent with the same token until it expires.
+//
+// It behaves like SendZnsMessage. Journey token errors are reported in the response and can be told
+// apart with errors.Is on response.Err(), e.g. errors.Is(response.Err(), ErrJourneyTokenExpired).
+// An empty journeyToken is not sent and gets a ZNS_JOURNEY_TOKEN_MISSING response.
+func (z *ZaloClient) SendZnsJourneyMessage(ctx context.Context, journeyToken string, request ZnsSendMsgRequest) (ZnsSendMsgReponse, error) {
+	if journeyToken == "" {
+		return ZnsSendMsgReponse{Error: ErrJourneyTokenMissing.Code, Message: ErrJourneyTokenMissing.Message}, nil
+	}
+	request.JourneyToken = journeyToken
+	return z.SendZnsMessage(ctx, request)
+}
@@ -0,0 +1,61 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+func TestSendZnsJourneyMessage(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	srv.RequireJourney(100)
+
+	zc := srv.Client()
+	ctx := context.Background()
+	request := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "100", TemplateData: map[string]string{"cost": "1"}}
+	journey := srv.IssueJourneyToken(time.Hour)
+
+	for i := 0; i < 2; i++ {
+		response, err := zc.SendZnsJourneyMessage(ctx, journey, request)
+		require.NoError(t, err)
+		assert.NoError(t, response.Err(), "messages of a journey share its token")
+	}
+	messages := srv.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, journey, messages[1].JourneyToken)
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"missing", "", client.ErrJourneyTokenMissing},
+		{"invalid", "unknown", client.ErrJourneyTokenInvalid},
+		{"expired", srv.IssueJourneyToken(-time.Minute), client.ErrJourneyTokenExpired},
+	}
+	for _, tt := range tests {
+		response, err := zc.SendZnsJourneyMessage(ctx, tt.token, request)
+		require.NoError(t, err, tt.name)
+		assert.ErrorIs(t, response.Err(), tt.want, tt.name)
+		for _, other := range []error{client.ErrJourneyTokenMissing, client.ErrJourneyTokenInvalid, client.ErrJourneyTokenExpired} {
+			if other != tt.want {
+				assert.False(t, errors.Is(response.Err(), other), tt.name)
+			}
+		}
+	}
+
+	response, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.ErrorIs(t, response.Err(), client.ErrJourneyTokenMissing, "journey templates require a token")
+
+	var znsErr *client.ZnsError
+	require.True(t, errors.As(response.Err(), &znsErr))
+	assert.Equal(t, client.ZNS_JOURNEY_TOKEN_MISSING, znsErr.Code)
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/x/znsformat"
+)
+
+// VietnamLocation is the Asia/Ho_Chi_Minh time zone, UTC+7 without daylight saving time.
+// ZNS quotas reset and quiet hours are counted in this time zone.
+// It is the location znsformat formats dates in.
+var VietnamLocation = znsformat.VietnamLocation
+
+// QuotaTracker tracks the daily ZNS quota of an Official Account from send responses.
+//
+// The quota resets at midnight Vietnam time, so a value reported on a previous day is unknown.
+// A QuotaTracker is safe for concurrent use.
+type QuotaTracker struct {
+	mu        sync.Mutex
+	daily     int
+	remaining int
+	updatedAt time.Time
+	now       func() time.Time
+}
+
+// NewQuotaTracker creates a tracker with an unknown quota.
+func NewQuotaTracker() *QuotaTracker {
+	return &QuotaTracker{now: time.Now}
+}
+
+// Update sets the daily quota and the quota remaining today.
+func (q *QuotaTracker) Update(daily, remaining int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.daily = daily
+	q.remaining = remaining
+	q.updatedAt = q.now()
+}
+
+// Remaining returns the quota remaining today, and whether it is known.
+func (q *QuotaTracker) Remaining() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.knownToday() {
+		return 0, false
+	}
+	return q.remaining, true
+}
+
+// Daily returns the daily quota, and whether it is known.
+func (q *QuotaTracker) Daily() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.updatedAt.IsZero() {
+		return 0, false
+	}
+	return q.daily, true
+}
+
+// Exhausted reports whether the quota is known to be used up for today.
+func (q *QuotaTracker) Exhausted() bool {
+	remaining, known := q.Remaining()
+	return known && remaining <= 0
+}
+
+// Record updates the tracker from a send response: a successful response carries the
+// current quota, and an out of quota error means no quota is left today.
+func (q *QuotaTracker) Record(response ZnsSendMsgReponse) {
+	switch response.Error {
+	case SUCCESS:
+		daily, err := strconv.Atoi(response.Data.Quota.DailyQuota)
+		if err != nil {
+			return
+		}
+		remaining, err := strconv.Atoi(response.Data.Quota.RemainingQuota)
+		if err != nil {
+			return
+		}
+		q.Update(daily, remaining)
+	case OUT_OF_QUOTA, ZNS_OUT_OF_DAILY_QUOTA:
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.remaining = 0
+		q.updatedAt = q.now()
+	}
+}
+
+// knownToday reports whether the quota was updated today, Vietnam time. The caller holds mu.
+func (q *QuotaTracker) knownToday() bool {
+	if q.updatedAt.IsZero() {
+		return false
+	}
+	y1, m1, d1 := q.updatedAt.In(VietnamLocation).Date()
+	y2, m2, d2 := q.now().In(VietnamLocation).Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+// UseQuotaTracker sets the tracker updated by every ZNS send response. Passing nil removes it.
+func (z *ZaloClient) UseQuotaTracker(tracker *QuotaTracker) {
+	z.quotaTracker = tracker
+}
+
+// GetQuotaTracker returns the quota tracker of the client, or nil if there is none.
+func (z *ZaloClient) GetQuotaTracker() *QuotaTracker {
+	return z.quotaTracker
+}
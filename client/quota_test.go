@@ -0,0 +1,43 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaTracker(t *testing.T) {
+	// 23:00 in Vietnam
+	now := time.Date(2024, 5, 1, 16, 0, 0, 0, time.UTC)
+	tracker := NewQuotaTracker()
+	tracker.now = func() time.Time { return now }
+
+	_, known := tracker.Remaining()
+	assert.False(t, known)
+	assert.False(t, tracker.Exhausted())
+
+	var ok ZnsSendMsgReponse
+	ok.Data.Quota.DailyQuota = "500"
+	ok.Data.Quota.RemainingQuota = "1"
+	tracker.Record(ok)
+	remaining, known := tracker.Remaining()
+	assert.True(t, known)
+	assert.Equal(t, 1, remaining)
+
+	tracker.Record(ZnsSendMsgReponse{Error: OUT_OF_QUOTA})
+	assert.True(t, tracker.Exhausted())
+
+	tracker.Record(ZnsSendMsgReponse{Error: TEMPLATE_ID_INVALID})
+	assert.True(t, tracker.Exhausted(), "unrelated errors do not change the quota")
+
+	// 00:30 the next day in Vietnam, the quota has been reset
+	now = now.Add(90 * time.Minute)
+	_, known = tracker.Remaining()
+	assert.False(t, known)
+	assert.False(t, tracker.Exhausted())
+
+	daily, known := tracker.Daily()
+	assert.True(t, known)
+	assert.Equal(t, 500, daily)
+}
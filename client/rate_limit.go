@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+)
+
+// EndpointGroup identifies a group of Zalo API endpoints sharing a rate limit.
+type EndpointGroup int
+
+const (
+	ENDPOINT_GROUP_SEND     EndpointGroup = iota + 1 // ZNS message sending
+	ENDPOINT_GROUP_TEMPLATE                          // ZNS template management
+	ENDPOINT_GROUP_OAUTH                             // OAuth access token
+)
+
+// RateLimiter limits the rate of requests sent to the Zalo API.
+//
+// Wait blocks until a request may be sent, or returns an error if ctx is done first.
+// A *ratelimit.TokenBucket from the x/ratelimit package satisfies this interface.
+// Implementations must be safe for concurrent use.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// UseRateLimiter sets the rate limiter applied to every request of an endpoint group.
+//
+// The same limiter may be shared by several ZaloClient instances representing the
+// same Official Account, so that they respect a single combined limit.
+// Passing nil removes the rate limiter of the group.
+func (z *ZaloClient) UseRateLimiter(group EndpointGroup, limiter RateLimiter) {
+	if limiter == nil {
+		delete(z.rateLimiters, group)
+		return
+	}
+	if z.rateLimiters == nil {
+		z.rateLimiters = make(map[EndpointGroup]RateLimiter)
+	}
+	z.rateLimiters[group] = limiter
+}
+
+// GetRateLimiter returns the rate limiter of an endpoint group, or nil if there is none.
+func (z *ZaloClient) GetRateLimiter(group EndpointGroup) RateLimiter {
+	return z.rateLimiters[group]
+}
+
+// limiterError wraps an error returned by RateLimiter.Wait, so that callers can tell that
+// the request was never sent. It unwraps to the limiter error, e.g. the context error.
+type limiterError struct {
+	err error
+}
+
+func (e *limiterError) Error() string { return e.err.Error() }
+
+func (e *limiterError) Unwrap() error { return e.err }
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/x/ratelimit"
+)
+
+func TestUseRateLimiterSharedAcrossClients(t *testing.T) {
+	calls := 0
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"error":0,"message":"Success"}`)),
+		}, nil
+	})
+
+	limiter, err := ratelimit.NewTokenBucket(0.001, 1)
+	require.NoError(t, err)
+
+	first := NewZaloClient("app-id", "secret", "verifier")
+	first.UseHTTPClient(&http.Client{Transport: transport})
+	first.UseRateLimiter(ENDPOINT_GROUP_SEND, limiter)
+
+	second := NewZaloClient("app-id", "secret", "verifier")
+	second.UseHTTPClient(&http.Client{Transport: transport})
+	second.UseRateLimiter(ENDPOINT_GROUP_SEND, limiter)
+	for _, zc := range []*ZaloClient{first, second} {
+		zc.recordTemplate("", false) // Known not to be an E2EE template, so only sends are made
+	}
+
+	_, err = first.SendZnsMessage(context.Background(), ZnsSendMsgRequest{Phone: "84987654321"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = second.SendZnsMessage(ctx, ZnsSendMsgRequest{Phone: "84987654321"})
+	assert.ErrorIs(t, err, context.Canceled, "the shared bucket is empty")
+
+	assert.Equal(t, 1, calls)
+
+	_, err = second.GetZnsTemplateList(context.Background(), ZnsTplListRequest{})
+	require.NoError(t, err, "other endpoint groups are not limited")
+	assert.Equal(t, 2, calls)
+
+	second.UseRateLimiter(ENDPOINT_GROUP_SEND, nil)
+	_, err = second.SendZnsMessage(context.Background(), ZnsSendMsgRequest{Phone: "84987654321"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
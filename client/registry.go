@@ -0,0 +1,250 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TOKEN_REFRESH_MARGIN is how long before expiry a registry refreshes the access token of a tenant.
+const TOKEN_REFRESH_MARGIN = 5 * time.Minute
+
+var (
+	ErrTenantNotFound = errors.New("zalo: tenant not found")
+	ErrTenantExists   = errors.New("zalo: tenant already exists")
+)
+
+// TenantConfig configures an Official Account managed by a Registry.
+type TenantConfig struct {
+	Key          string        // Routing key of the tenant, e.g. the OA ID or app ID
+	AppID        string        // App ID of the OA
+	SecretKey    string        // App secret key
+	CodeVerifier string        // PKCE code verifier
+	TokenStore   TokenStore    // Where the token of the OA is persisted, in memory by default
+	RateLimiter  RateLimiter   // Rate limiter applied to ZNS sends, none by default
+	QuotaTracker *QuotaTracker // Quota tracker of the OA, a new one by default
+
+	// Configure, if set, is called with the new client to apply any other setting,
+	// e.g. the HTTP client, logger or circuit breaker.
+	Configure func(z *ZaloClient)
+}
+
+// Tenant is an Official Account managed by a Registry.
+type Tenant struct {
+	Key          string
+	Client       *ZaloClient
+	TokenStore   TokenStore
+	QuotaTracker *QuotaTracker
+
+	mu sync.Mutex // serializes token refreshes
+}
+
+// Registry manages the clients of several Official Accounts, keyed by tenant key,
+// each with its own credentials, token store, quota tracker and rate limiter.
+// Tenants can be added and removed at runtime. A Registry is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	tenants map[string]*Tenant
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{tenants: make(map[string]*Tenant)}
+}
+
+// Add creates the client of a tenant and loads its token from the token store.
+// A token store without token is not an error: the token can be set later with SaveToken.
+// It returns ErrTenantExists if a tenant with the same key is already registered,
+// without loading the token.
+func (r *Registry) Add(ctx context.Context, config TenantConfig) (*Tenant, error) {
+	r.mu.RLock()
+	_, exists := r.tenants[config.Key]
+	r.mu.RUnlock()
+	if exists {
+		return nil, fmt.Errorf("%w: %s", ErrTenantExists, config.Key)
+	}
+
+	if config.TokenStore == nil {
+		config.TokenStore = &MemoryTokenStore{}
+	}
+	if config.QuotaTracker == nil {
+		config.QuotaTracker = NewQuotaTracker()
+	}
+
+	zc := NewZaloClient(config.AppID, config.SecretKey, config.CodeVerifier)
+	zc.UseQuotaTracker(config.QuotaTracker)
+	if config.RateLimiter != nil {
+		zc.UseRateLimiter(ENDPOINT_GROUP_SEND, config.RateLimiter)
+	}
+	if config.Configure != nil {
+		config.Configure(zc)
+	}
+
+	token, err := config.TokenStore.LoadToken(ctx)
+	switch {
+	case err == nil:
+		zc.SetAccessToken(token)
+	case !errors.Is(err, ErrTokenNotFound):
+		return nil, fmt.Errorf("zalo: loading token of tenant %s: %w", config.Key, err)
+	}
+
+	tenant := &Tenant{
+		Key:          config.Key,
+		Client:       zc,
+		TokenStore:   config.TokenStore,
+		QuotaTracker: config.QuotaTracker,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tenants[config.Key]; ok {
+		// Added concurrently
+		return nil, fmt.Errorf("%w: %s", ErrTenantExists, config.Key)
+	}
+	r.tenants[config.Key] = tenant
+	return tenant, nil
+}
+
+// Remove unregisters a tenant and reports whether it was registered.
+func (r *Registry) Remove(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.tenants[key]
+	delete(r.tenants, key)
+	return ok
+}
+
+// Get returns a tenant, or ErrTenantNotFound.
+func (r *Registry) Get(key string) (*Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenant, ok := r.tenants[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTenantNotFound, key)
+	}
+	return tenant, nil
+}
+
+// Keys returns the keys of the registered tenants, sorted.
+func (r *Registry) Keys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]string, 0, len(r.tenants))
+	for key := range r.tenants {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CheckTokenExpiry calls CheckTokenExpiry on the client of every tenant, so that tenants
+// that send nothing for a while are also warned before their refresh token expires.
+func (r *Registry) CheckTokenExpiry(ctx context.Context) {
+	r.mu.RLock()
+	tenants := make([]*Tenant, 0, len(r.tenants))
+	for _, tenant := range r.tenants {
+		tenants = append(tenants, tenant)
+	}
+	r.mu.RUnlock()
+
+	for _, tenant := range tenants {
+		tenant.Client.CheckTokenExpiry(ctx)
+	}
+}
+
+// Do calls fn with the client of a tenant, refreshing its access token first if it is
+// about to expire, so that any API method can be called on behalf of a tenant, e.g.
+//
+//	err := registry.Do(ctx, key, func(zc *ZaloClient) (err error) {
+//		response, err = zc.CreateZnsTemplate(ctx, request)
+//		return err
+//	})
+//
+// It returns ErrTenantNotFound, the refresh error, or the error returned by fn.
+func (r *Registry) Do(ctx context.Context, key string, fn func(zc *ZaloClient) error) error {
+	tenant, err := r.Get(key)
+	if err != nil {
+		return err
+	}
+	if err := tenant.EnsureAccessToken(ctx, TOKEN_REFRESH_MARGIN); err != nil {
+		return err
+	}
+	return fn(tenant.Client)
+}
+
+// SendZnsMessage sends a ZNS message on behalf of a tenant,
+// refreshing its access token first if it is about to expire.
+func (r *Registry) SendZnsMessage(ctx context.Context, key string, request ZnsSendMsgRequest) (ZnsSendMsgReponse, error) {
+	var response ZnsSendMsgReponse
+	err := r.Do(ctx, key, func(zc *ZaloClient) (err error) {
+		response, err = zc.SendZnsMessage(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// GetZnsTemplateList gets the ZNS templates of a tenant,
+// refreshing its access token first if it is about to expire.
+func (r *Registry) GetZnsTemplateList(ctx context.Context, key string, request ZnsTplListRequest) (ZnsTplListResponse, error) {
+	var response ZnsTplListResponse
+	err := r.Do(ctx, key, func(zc *ZaloClient) (err error) {
+		response, err = zc.GetZnsTemplateList(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// GetZnsTemplateDetail gets a ZNS template of a tenant,
+// refreshing its access token first if it is about to expire.
+func (r *Registry) GetZnsTemplateDetail(ctx context.Context, key string, templateID string) (ZnsTplDetailResponse, error) {
+	var response ZnsTplDetailResponse
+	err := r.Do(ctx, key, func(zc *ZaloClient) (err error) {
+		response, err = zc.GetZnsTemplateDetail(ctx, templateID)
+		return err
+	})
+	return response, err
+}
+
+// SaveToken sets the token of the tenant client and persists it in the token store,
+// e.g. after exchanging a new authorization code.
+func (t *Tenant) SaveToken(ctx context.Context, token AccessToken) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.saveToken(ctx, token)
+}
+
+// EnsureAccessToken refreshes the access token of the tenant if it expires within margin,
+// and persists the new token in the token store.
+// A token whose expiry is unknown is not refreshed.
+func (t *Tenant) EnsureAccessToken(ctx context.Context, margin time.Duration) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	token := t.Client.GetAccessToken()
+	if !token.ExpiresWithin(margin) {
+		return nil
+	}
+
+	refreshed, err := t.Client.RefreshAccessToken(ctx, AccessTokenRequest{RefreshToken: token.RefreshToken})
+	if err != nil {
+		return fmt.Errorf("zalo: refreshing token of tenant %s: %w", t.Key, err)
+	}
+	return t.saveToken(ctx, refreshed)
+}
+
+// saveToken sets and persists a token. The caller holds mu.
+func (t *Tenant) saveToken(ctx context.Context, token AccessToken) error {
+	t.Client.SetAccessToken(token)
+	if err := t.TokenStore.SaveToken(ctx, t.Client.GetAccessToken()); err != nil {
+		t.Client.GetLogger().ErrorContext(ctx, "Error saving token:", slog.String("tenant", t.Key), slog.Any("err", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func addTestTenant(t *testing.T, registry *client.Registry, key string, srv *zalotest.Server, store client.TokenStore) *client.Tenant {
+	t.Helper()
+
+	tenant, err := registry.Add(context.Background(), client.TenantConfig{
+		Key:          key,
+		AppID:        zalotest.APP_ID,
+		SecretKey:    zalotest.SECRET_KEY,
+		CodeVerifier: zalotest.CODE_VERIFIER,
+		TokenStore:   store,
+		Configure:    srv.Configure,
+	})
+	require.NoError(t, err)
+	return tenant
+}
+
+func TestRegistryRoutesByTenant(t *testing.T) {
+	brandA, brandB := newSendTestServer(), newSendTestServer()
+	defer brandA.Close()
+	defer brandB.Close()
+	brandB.SetQuota(100, 10)
+
+	ctx := context.Background()
+	registry := client.NewRegistry()
+	tenantA := addTestTenant(t, registry, "brand-a", brandA, nil)
+	tenantB := addTestTenant(t, registry, "brand-b", brandB, nil)
+	require.NoError(t, tenantA.SaveToken(ctx, brandA.IssueToken()))
+	require.NoError(t, tenantB.SaveToken(ctx, brandB.IssueToken()))
+	assert.Equal(t, []string{"brand-a", "brand-b"}, registry.Keys())
+
+	request := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "100", TemplateData: map[string]string{"cost": "1"}}
+	response, err := registry.SendZnsMessage(ctx, "brand-b", request)
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+	assert.Empty(t, brandA.Messages())
+	assert.Len(t, brandB.Messages(), 1)
+
+	remaining, known := tenantB.QuotaTracker.Remaining()
+	assert.True(t, known)
+	assert.Equal(t, 9, remaining)
+	_, known = tenantA.QuotaTracker.Remaining()
+	assert.False(t, known)
+
+	_, err = registry.Add(ctx, client.TenantConfig{Key: "brand-a"})
+	assert.ErrorIs(t, err, client.ErrTenantExists)
+
+	assert.True(t, registry.Remove("brand-b"))
+	assert.False(t, registry.Remove("brand-b"))
+	_, err = registry.SendZnsMessage(ctx, "brand-b", request)
+	assert.ErrorIs(t, err, client.ErrTenantNotFound)
+}
+
+func TestRegistryRefreshesAndPersistsToken(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	store := &client.FileTokenStore{Path: filepath.Join(t.TempDir(), "brand-a.json")}
+	expiring := srv.IssueToken()
+	expiring.ExpiresAt = time.Now().Add(time.Minute)
+	require.NoError(t, store.SaveToken(ctx, expiring))
+
+	registry := client.NewRegistry()
+	tenant := addTestTenant(t, registry, "brand-a", srv, store)
+	assert.Equal(t, expiring.AccessToken, tenant.Client.GetAccessToken().AccessToken, "the stored token is loaded")
+
+	_, err := registry.GetZnsTemplateList(ctx, "brand-a", client.ZnsTplListRequest{})
+	require.NoError(t, err)
+
+	refreshed := tenant.Client.GetAccessToken()
+	assert.NotEqual(t, expiring.AccessToken, refreshed.AccessToken)
+	assert.False(t, refreshed.ExpiresWithin(client.TOKEN_REFRESH_MARGIN))
+
+	stored, err := store.LoadToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, refreshed.AccessToken, stored.AccessToken)
+	assert.True(t, refreshed.ExpiresAt.Equal(stored.ExpiresAt))
+}
+
+func TestRegistryDo(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	registry := client.NewRegistry()
+	tenant := addTestTenant(t, registry, "brand-a", srv, nil)
+	expiring := srv.IssueToken()
+	expiring.ExpiresAt = time.Now().Add(time.Minute)
+	require.NoError(t, tenant.SaveToken(ctx, expiring))
+
+	var response client.RsaKeyResponse
+	err := registry.Do(ctx, "brand-a", func(zc *client.ZaloClient) (err error) {
+		assert.Same(t, tenant.Client, zc)
+		assert.NotEqual(t, expiring.AccessToken, zc.GetAccessToken().AccessToken, "the token is refreshed first")
+		response, err = zc.GenerateRsaKey(ctx)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+
+	failure := errors.New("failure")
+	assert.Equal(t, failure, registry.Do(ctx, "brand-a", func(zc *client.ZaloClient) error { return failure }))
+
+	called := false
+	err = registry.Do(ctx, "brand-b", func(zc *client.ZaloClient) error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, client.ErrTenantNotFound)
+	assert.False(t, called)
+}
+
+// countingTokenStore counts the tokens loaded.
+type countingTokenStore struct {
+	client.MemoryTokenStore
+	loads int
+}
+
+func (s *countingTokenStore) LoadToken(ctx context.Context) (client.AccessToken, error) {
+	s.loads++
+	return s.MemoryTokenStore.LoadToken(ctx)
+}
+
+func TestRegistryAddExisting(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+
+	registry := client.NewRegistry()
+	tenant := addTestTenant(t, registry, "brand-a", srv, nil)
+
+	store := &countingTokenStore{}
+	configured := false
+	_, err := registry.Add(context.Background(), client.TenantConfig{
+		Key:        "brand-a",
+		TokenStore: store,
+		Configure:  func(zc *client.ZaloClient) { configured = true },
+	})
+	assert.ErrorIs(t, err, client.ErrTenantExists)
+	assert.Zero(t, store.loads, "the token of a duplicate tenant is not loaded")
+	assert.False(t, configured, "the client of a duplicate tenant is not built")
+
+	existing, err := registry.Get("brand-a")
+	require.NoError(t, err)
+	assert.Same(t, tenant, existing)
+}
+
+func TestRegistryRefreshWhileSending(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	registry := client.NewRegistry()
+	tenant := addTestTenant(t, registry, "brand-a", srv, nil)
+	require.NoError(t, tenant.SaveToken(ctx, srv.IssueToken()))
+
+	// Run with -race: tokens are saved while requests read them
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, tenant.SaveToken(ctx, srv.IssueToken()))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		request := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "100", TemplateData: map[string]string{"cost": "1"}}
+		for i := 0; i < 20; i++ {
+			response, err := registry.SendZnsMessage(ctx, "brand-a", request)
+			if assert.NoError(t, err) {
+				assert.Equal(t, client.SUCCESS, response.Error)
+			}
+		}
+	}()
+	wg.Wait()
+	assert.Len(t, srv.Messages(), 20)
+}
+
+func TestFileTokenStoreEmpty(t *testing.T) {
+	store := &client.FileTokenStore{Path: filepath.Join(t.TempDir(), "missing.json")}
+	_, err := store.LoadToken(context.Background())
+	assert.ErrorIs(t, err, client.ErrTokenNotFound)
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// do sends an HTTP request to the Zalo API on behalf of the client.
+//
+// Every API method goes through do instead of calling the HTTP client directly,
+// so that cross-cutting behaviour such as rate limiting, token expiry checks,
+// appsecret_proof, circuit breaking and debug dumping is applied uniformly.
+// The group selects the rate limiter to wait on before sending.
+// The request is bound to ctx before it is sent.
+func (z *ZaloClient) do(ctx context.Context, group EndpointGroup, req *http.Request) (*http.Response, error) {
+	breaker := z.GetCircuitBreaker()
+	if group == ENDPOINT_GROUP_OAUTH {
+		// OAuth requests are neither authenticated nor sent to the business API
+		breaker = nil
+	}
+	if breaker != nil {
+		// Fail fast while the circuit is open, without holding a rate limiter token
+		if err := breaker.allow(); err != nil {
+			z.GetLogger().ErrorContext(ctx, "Error sending request:", slog.Any("err", err))
+			return nil, err
+		}
+	}
+
+	if limiter := z.GetRateLimiter(group); limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			if breaker != nil {
+				breaker.record(circuitIgnored)
+			}
+			z.GetLogger().ErrorContext(ctx, "Error waiting for rate limiter:", slog.Any("err", err))
+			return nil, &limiterError{err: err}
+		}
+	}
+
+	if group != ENDPOINT_GROUP_OAUTH {
+		z.CheckTokenExpiry(ctx)
+		z.setAppSecretProof(req)
+	}
+
+	req = req.WithContext(ctx)
+
+	var reqBody []byte
+	if z.debugWriter != nil {
+		reqBody = z.snapshotRequestBody(ctx, req)
+	}
+
+	start := time.Now()
+	resp, err := z.GetHTTPClient().Do(req)
+	if z.debugWriter != nil {
+		z.dumpExchange(ctx, req, reqBody, resp, err, time.Since(start))
+	}
+	if breaker != nil {
+		breaker.record(classifyCircuitResult(ctx, resp, err))
+	}
+	return resp, err
+}
+
+// classifyCircuitResult tells whether an exchange counts as a failure of the Zalo API.
+// Network errors, 5xx statuses and UNKNOWN_ERROR responses are failures,
+// requests canceled by the caller are not counted at all.
+// The response body is read and replaced so that callers can still consume it.
+func classifyCircuitResult(ctx context.Context, resp *http.Response, err error) circuitResult {
+	if err != nil {
+		if ctx.Err() != nil {
+			return circuitIgnored
+		}
+		return circuitFailure
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return circuitFailure
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return circuitFailure
+	}
+
+	var errResp struct {
+		Error int `json:"error"`
+	}
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error == UNKNOWN_ERROR {
+		return circuitFailure
+	}
+	return circuitSuccess
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/ducminhgd/zalo-go-sdk/x/znsformat"
+)
+
+// ValidateTemplateData checks template data against the parameters of the template like Zalo does
+// when sending, so that mistakes are caught before sending. It returns nil, or a *ZnsError with
+// the code Zalo would return for the first problem found:
+//
+//   - TEMPLATE_DATA_MISSING_PARAMETER_NAME for a required parameter missing or empty
+//   - PARAMETER_NAME_DATA_BREAKS_LENGTH for a value too short or too long
+//   - PARAMETER_NAME_HAS_INVALID_FORMAT for a NUMBER, DATE or CURRENCY value in the wrong format,
+//     see the znsformat package for the accepted formats
+//   - TEMPLATE_DATA_TYPE_IS_NOT_DEFINED for a parameter not defined by the template
+func (d ZnsTplDetailData) ValidateTemplateData(data map[string]string) error {
+	for _, param := range d.ListParams {
+		value, ok := data[param.Name]
+		if err := param.Check(value, ok); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !d.hasParam(name) {
+			return &ZnsError{Code: TEMPLATE_DATA_TYPE_IS_NOT_DEFINED, Message: fmt.Sprintf("Parameter %s is not defined", name)}
+		}
+	}
+	return nil
+}
+
+// Check checks the value of the parameter in template data, where ok tells whether the data
+// has the parameter. It returns nil, or a *ZnsError with the code of ValidateTemplateData for
+// the problem found, other than TEMPLATE_DATA_TYPE_IS_NOT_DEFINED.
+func (p ZnsTplDetailParam) Check(value string, ok bool) error {
+	if !ok || (value == "" && !p.AcceptNull) {
+		if p.Require {
+			return &ZnsError{Code: TEMPLATE_DATA_MISSING_PARAMETER_NAME, Message: fmt.Sprintf("Template data is missing parameter %s", p.Name)}
+		}
+		return nil
+	}
+	if value == "" {
+		// Accepted empty value, not subject to the length or the format
+		return nil
+	}
+	length := utf8.RuneCountInString(value)
+	if (p.MaxLength > 0 && length > p.MaxLength) || length < p.MinLength {
+		return &ZnsError{Code: PARAMETER_NAME_DATA_BREAKS_LENGTH, Message: fmt.Sprintf("Parameter %s breaks length", p.Name)}
+	}
+	if !validFormat(p.Type, value) {
+		return &ZnsError{Code: PARAMETER_NAME_HAS_INVALID_FORMAT, Message: fmt.Sprintf("Parameter %s is not a valid %s", p.Name, p.Type)}
+	}
+	return nil
+}
+
+func (d ZnsTplDetailData) hasParam(name string) bool {
+	for _, param := range d.ListParams {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validFormat reports whether a value has the format of a parameter type.
+func validFormat(paramType, value string) bool {
+	switch paramType {
+	case ZNS_TPL_PARAM_TYPE_NUMBER:
+		return znsformat.IsNumber(value)
+	case ZNS_TPL_PARAM_TYPE_DATE:
+		return znsformat.IsDate(value)
+	case ZNS_TPL_PARAM_TYPE_CURRENCY:
+		return znsformat.IsCurrency(value)
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/x/znsformat"
+)
+
+func TestValidateTemplateData(t *testing.T) {
+	template := client.ZnsTplDetailData{
+		TemplateID: 100,
+		ListParams: []client.ZnsTplDetailParam{
+			{Name: "name", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_STRING, MaxLength: 30},
+			{Name: "cost", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_NUMBER, MaxLength: 20},
+			{Name: "date", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_DATE, MaxLength: 20},
+			{Name: "fee", Require: false, Type: client.ZNS_TPL_PARAM_TYPE_CURRENCY, MaxLength: 20},
+		},
+	}
+	valid := map[string]string{
+		"name": "Nguyễn Văn A",
+		"cost": znsformat.Number(250000),
+		"date": znsformat.Date(time.Now()),
+		"fee":  znsformat.Currency(15000),
+	}
+
+	tests := []struct {
+		name   string
+		change map[string]string
+		want   int
+	}{
+		{"valid", nil, client.SUCCESS},
+		{"hand formatted", map[string]string{"cost": "250,000", "fee": "15,000 đồng"}, client.SUCCESS},
+		{"missing", map[string]string{"name": ""}, client.TEMPLATE_DATA_MISSING_PARAMETER_NAME},
+		{"too long", map[string]string{"name": "Nguyễn Văn A Nguyễn Văn A Nguyễn Văn A"}, client.PARAMETER_NAME_DATA_BREAKS_LENGTH},
+		{"not a number", map[string]string{"cost": "250k"}, client.PARAMETER_NAME_HAS_INVALID_FORMAT},
+		{"not a date", map[string]string{"date": "2024-03-09"}, client.PARAMETER_NAME_HAS_INVALID_FORMAT},
+		{"not a currency", map[string]string{"fee": "15 USD"}, client.PARAMETER_NAME_HAS_INVALID_FORMAT},
+		{"undefined", map[string]string{"discount": "10"}, client.TEMPLATE_DATA_TYPE_IS_NOT_DEFINED},
+	}
+	for _, tt := range tests {
+		data := make(map[string]string)
+		for k, v := range valid {
+			data[k] = v
+		}
+		for k, v := range tt.change {
+			data[k] = v
+		}
+
+		err := template.ValidateTemplateData(data)
+		if tt.want == client.SUCCESS {
+			assert.NoError(t, err, tt.name)
+			continue
+		}
+		assert.ErrorIs(t, err, &client.ZnsError{Code: tt.want}, tt.name)
+	}
+}
+
+func TestZnsTplDetailParamCheck(t *testing.T) {
+	param := client.ZnsTplDetailParam{Name: "otp", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_NUMBER, MinLength: 6, MaxLength: 6}
+
+	assert.NoError(t, param.Check("123456", true))
+	assert.ErrorIs(t, param.Check("", false), &client.ZnsError{Code: client.TEMPLATE_DATA_MISSING_PARAMETER_NAME})
+	assert.ErrorIs(t, param.Check("1234", true), &client.ZnsError{Code: client.PARAMETER_NAME_DATA_BREAKS_LENGTH})
+	assert.ErrorIs(t, param.Check("12345a", true), &client.ZnsError{Code: client.PARAMETER_NAME_HAS_INVALID_FORMAT})
+
+	param.AcceptNull = true
+	assert.NoError(t, param.Check("", true), "an accepted empty value is not checked against the minimum length")
+
+	param.Require = false
+	assert.NoError(t, param.Check("", false), "optional parameters may be missing")
+}
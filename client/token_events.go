@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// ErrRefreshTokenExpired is reported to the reauthorization callback when the refresh token has expired.
+var ErrRefreshTokenExpired = errors.New("zalo: refresh token has expired")
+
+// OAUTH_REFRESH_TOKEN_INVALID is the code of the *OAuthError returned by the Zalo OAuth server
+// for a refresh token that is invalid, revoked, expired or already used.
+const OAUTH_REFRESH_TOKEN_INVALID = -14014
+
+// GetAuthorizationURL returns the URL an OA admin opens to grant the app access to the OA.
+//
+// It starts a new PKCE authorization flow with the code challenge of the client:
+// once granted, Zalo redirects to redirectURI with the authorization code and state,
+// and the code can be exchanged with RequestAccessToken.
+func (z *ZaloClient) GetAuthorizationURL(redirectURI, state string) string {
+	query := url.Values{}
+	query.Set("app_id", z.appID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("code_challenge", z.codeChallenge)
+	query.Set("state", state)
+	return z.endpoint(ENDPOINT_OA_PERMISSION) + "?" + query.Encode()
+}
+
+// OnRefreshTokenExpiring registers a callback called when the refresh token of the client
+// expires within window, so that someone can redo the authorization flow before the OA goes dark.
+//
+// The expiry is checked by CheckTokenExpiry, which runs before every authenticated request,
+// and the callback is called once per refresh token. Passing a nil callback removes it.
+func (z *ZaloClient) OnRefreshTokenExpiring(window time.Duration, fn func(ctx context.Context, token AccessToken)) {
+	z.eventsMu.Lock()
+	defer z.eventsMu.Unlock()
+	z.refreshExpiringWindow = window
+	z.onRefreshExpiring = fn
+}
+
+// OnReauthorizationRequired registers a callback called when the OA must be authorized again:
+// when RefreshAccessToken is rejected by the Zalo OAuth server with an *OAuthError of code
+// OAUTH_REFRESH_TOKEN_INVALID, or when CheckTokenExpiry finds a refresh token past its expiry,
+// with ErrRefreshTokenExpired. Other OAuth errors, e.g. UNKNOWN_ERROR, are transient or
+// configuration errors that a new authorization would not fix, and do not call it.
+//
+// The callback is called once per refresh token. Passing nil removes it.
+func (z *ZaloClient) OnReauthorizationRequired(fn func(ctx context.Context, err error)) {
+	z.eventsMu.Lock()
+	defer z.eventsMu.Unlock()
+	z.onReauthRequired = fn
+}
+
+// CheckTokenExpiry calls the callbacks registered with OnRefreshTokenExpiring and
+// OnReauthorizationRequired if the refresh token of the client is expiring or expired.
+//
+// It runs before every authenticated request; an OA that sends nothing for a while is only
+// warned if CheckTokenExpiry is also called periodically, e.g. once a day.
+func (z *ZaloClient) CheckTokenExpiry(ctx context.Context) {
+	token := z.GetAccessToken()
+	if token.RefreshToken == "" || token.RefreshTokenExpiresAt.IsZero() {
+		return
+	}
+
+	if !token.RefreshTokenValid() {
+		z.reauthorizationRequired(ctx, token.RefreshToken, ErrRefreshTokenExpired)
+		return
+	}
+
+	z.eventsMu.Lock()
+	fn := z.onRefreshExpiring
+	notify := fn != nil &&
+		token.RefreshTokenExpiresWithin(z.refreshExpiringWindow) &&
+		z.refreshExpiringNotified != token.RefreshToken
+	if notify {
+		z.refreshExpiringNotified = token.RefreshToken
+	}
+	z.eventsMu.Unlock()
+
+	if notify {
+		fn(ctx, token)
+	}
+}
+
+// reauthorizationRequired calls the reauthorization callback once for a refresh token.
+func (z *ZaloClient) reauthorizationRequired(ctx context.Context, refreshToken string, err error) {
+	z.eventsMu.Lock()
+	fn := z.onReauthRequired
+	notify := fn != nil && z.reauthNotified != refreshToken
+	if notify {
+		z.reauthNotified = refreshToken
+	}
+	z.eventsMu.Unlock()
+
+	if notify {
+		fn(ctx, err)
+	}
+}
+
+// refreshTokenRejected tells whether a token request failed because the refresh token was rejected.
+func refreshTokenRejected(err error) bool {
+	var oauthErr *OAuthError
+	return errors.As(err, &oauthErr) && oauthErr.Code == OAUTH_REFRESH_TOKEN_INVALID
+}
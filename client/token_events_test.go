@@ -0,0 +1,122 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func TestOnRefreshTokenExpiring(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+
+	zc := srv.Client()
+	token := zc.GetAccessToken()
+	token.RefreshTokenExpiresAt = time.Now().Add(48 * time.Hour)
+	zc.SetAccessToken(token)
+
+	var warned []client.AccessToken
+	zc.OnRefreshTokenExpiring(7*24*time.Hour, func(ctx context.Context, token client.AccessToken) {
+		warned = append(warned, token)
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := zc.GetZnsTemplateList(ctx, client.ZnsTplListRequest{})
+		require.NoError(t, err)
+	}
+	require.Len(t, warned, 1, "the callback is called once per refresh token")
+	assert.Equal(t, token.RefreshToken, warned[0].RefreshToken)
+
+	refreshed, err := zc.RefreshAccessToken(ctx, client.AccessTokenRequest{RefreshToken: token.RefreshToken})
+	require.NoError(t, err)
+	zc.SetAccessToken(refreshed)
+	_, err = zc.GetZnsTemplateList(ctx, client.ZnsTplListRequest{})
+	require.NoError(t, err)
+	assert.Len(t, warned, 1, "a fresh refresh token is not expiring")
+}
+
+func TestOnReauthorizationRequired(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+
+	zc := srv.Client()
+	var reasons []error
+	zc.OnReauthorizationRequired(func(ctx context.Context, err error) {
+		reasons = append(reasons, err)
+	})
+
+	ctx := context.Background()
+	token := zc.GetAccessToken()
+	srv.RevokeTokens()
+	_, err := zc.RefreshAccessToken(ctx, client.AccessTokenRequest{RefreshToken: token.RefreshToken})
+	require.Error(t, err)
+	_, _ = zc.RefreshAccessToken(ctx, client.AccessTokenRequest{RefreshToken: token.RefreshToken})
+
+	require.Len(t, reasons, 1)
+	var oauthErr *client.OAuthError
+	require.True(t, errors.As(reasons[0], &oauthErr))
+	assert.Equal(t, client.OAUTH_REFRESH_TOKEN_INVALID, oauthErr.Code)
+
+	// A transient error does not require a new authorization
+	srv.InjectError(client.ENDPOINT_GET_ACCESS_TOKEN, client.UNKNOWN_ERROR)
+	_, err = zc.RefreshAccessToken(ctx, client.AccessTokenRequest{RefreshToken: "another"})
+	require.True(t, errors.As(err, &oauthErr))
+	assert.Len(t, reasons, 1)
+
+	token = srv.IssueToken()
+	token.RefreshTokenExpiresAt = time.Now().Add(-time.Hour)
+	zc.SetAccessToken(token)
+	_, err = zc.GetZnsTemplateList(ctx, client.ZnsTplListRequest{})
+	require.NoError(t, err)
+	require.Len(t, reasons, 2)
+	assert.ErrorIs(t, reasons[1], client.ErrRefreshTokenExpired)
+}
+
+func TestCheckTokenExpiry(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+
+	registry := client.NewRegistry()
+	tenant, err := registry.Add(context.Background(), client.TenantConfig{
+		Key:       "oa-1",
+		Configure: srv.Configure,
+	})
+	require.NoError(t, err)
+	token := srv.IssueToken()
+	token.RefreshTokenExpiresAt = time.Now().Add(48 * time.Hour)
+	tenant.Client.SetAccessToken(token)
+
+	var warned []string
+	tenant.Client.OnRefreshTokenExpiring(7*24*time.Hour, func(ctx context.Context, token client.AccessToken) {
+		warned = append(warned, token.RefreshToken)
+	})
+
+	// An idle OA is warned without sending any request
+	registry.CheckTokenExpiry(context.Background())
+	registry.CheckTokenExpiry(context.Background())
+	assert.Equal(t, []string{token.RefreshToken}, warned)
+	assert.Empty(t, srv.Requests())
+}
+
+func TestGetAuthorizationURL(t *testing.T) {
+	zc := client.NewZaloClient("app-id", "secret", "verifier")
+
+	authURL, err := url.Parse(zc.GetAuthorizationURL("https://example.com/callback", "state-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://oauth.zaloapp.com/v4/oa/permission", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, url.Values{
+		"app_id":         {"app-id"},
+		"redirect_uri":   {"https://example.com/callback"},
+		"code_challenge": {zc.GetCodeChallenge()},
+		"state":          {"state-1"},
+	}, authURL.Query())
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMissingAccessToken is returned when a token response reports no error but carries no access token.
+var ErrMissingAccessToken = errors.New("zalo: token response has no access_token")
+
+// OAuthError is returned when the Zalo OAuth server rejects a token request.
+type OAuthError struct {
+	Code        int    // The error code, always non-zero
+	Name        string // The error name, if any
+	Description string // The error description or reason, if any
+}
+
+func (e *OAuthError) Error() string {
+	return fmt.Sprintf("code: %d, description: %s", e.Code, e.Description)
+}
+
+// flexInt is an integer that may be encoded in JSON either as a number or as a numeric string.
+// The Zalo OAuth server sends expires_in as a string, while other APIs send numbers.
+type flexInt struct {
+	Value int
+	Set   bool // Whether the field was present and not null
+}
+
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil
+		}
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		// Accept integral floats such as 90000.0
+		number, ferr := strconv.ParseFloat(text, 64)
+		if ferr != nil || number != float64(int(number)) {
+			return fmt.Errorf("invalid integer %s", data)
+		}
+		value = int(number)
+	}
+	f.Value, f.Set = value, true
+	return nil
+}
+
+// tokenResponse is the body of a response of the OAuth access token endpoint,
+// either a token or an error.
+type tokenResponse struct {
+	AccessToken      string  `json:"access_token"`
+	RefreshToken     string  `json:"refresh_token"`
+	ExpiresIn        flexInt `json:"expires_in"`
+	Error            flexInt `json:"error"`
+	ErrorName        string  `json:"error_name"`
+	ErrorReason      string  `json:"error_reason"`
+	ErrorDescription string  `json:"error_description"`
+	Message          string  `json:"message"`
+}
+
+// decodeTokenResponse decodes a response of the OAuth access token endpoint.
+//
+// A body with a non-zero error code is returned as an *OAuthError.
+// A body without error code must carry an access token and a valid expires_in.
+func decodeTokenResponse(body []byte) (AccessToken, error) {
+	var token AccessToken
+
+	var resp tokenResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return token, fmt.Errorf("zalo: decoding token response: %w", err)
+	}
+
+	if resp.Error.Set && resp.Error.Value != 0 {
+		oauthErr := &OAuthError{
+			Code:        resp.Error.Value,
+			Name:        resp.ErrorName,
+			Description: resp.ErrorDescription,
+		}
+		for _, description := range []string{resp.ErrorReason, resp.Message, resp.ErrorName} {
+			if oauthErr.Description == "" {
+				oauthErr.Description = description
+			}
+		}
+		return token, oauthErr
+	}
+
+	if resp.AccessToken == "" {
+		return token, ErrMissingAccessToken
+	}
+	if !resp.ExpiresIn.Set {
+		return token, errors.New("zalo: token response has no expires_in")
+	}
+
+	token.AccessToken = resp.AccessToken
+	token.RefreshToken = resp.RefreshToken
+	token.ExpiresIn = resp.ExpiresIn.Value
+	return token, nil
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeTokenResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		expected  AccessToken
+		oauthErr  *OAuthError
+		expectErr bool
+	}{
+		{
+			name:     "expires_in as string",
+			body:     `{"access_token":"at","refresh_token":"rt","expires_in":"90000"}`,
+			expected: AccessToken{AccessToken: "at", RefreshToken: "rt", ExpiresIn: 90000},
+		},
+		{
+			name:     "expires_in as number",
+			body:     `{"access_token":"at","refresh_token":"rt","expires_in":90000}`,
+			expected: AccessToken{AccessToken: "at", RefreshToken: "rt", ExpiresIn: 90000},
+		},
+		{
+			name:     "explicit zero error",
+			body:     `{"error":0,"access_token":"at","refresh_token":"rt","expires_in":"3600"}`,
+			expected: AccessToken{AccessToken: "at", RefreshToken: "rt", ExpiresIn: 3600},
+		},
+		{
+			name:     "error with description",
+			body:     `{"error_name":"Invalid parameter","error_reason":"Invalid refresh token","ref_doc":"https://developers.zalo.me/docs","error_description":"Refresh token is invalid","error":-14014}`,
+			oauthErr: &OAuthError{Code: -14014, Name: "Invalid parameter", Description: "Refresh token is invalid"},
+		},
+		{
+			name:     "error with reason only",
+			body:     `{"error":-14002,"error_name":"Invalid app_id","error_reason":"app_id is not existed"}`,
+			oauthErr: &OAuthError{Code: -14002, Name: "Invalid app_id", Description: "app_id is not existed"},
+		},
+		{
+			name:     "error code as string",
+			body:     `{"error":"-14019","error_description":"Invalid code verifier"}`,
+			oauthErr: &OAuthError{Code: -14019, Description: "Invalid code verifier"},
+		},
+		{
+			name:     "string fields only error",
+			body:     `{"error":"-118","message":"Zalo account is not existed"}`,
+			oauthErr: &OAuthError{Code: -118, Description: "Zalo account is not existed"},
+		},
+		{name: "missing access token", body: `{"refresh_token":"rt","expires_in":"90000"}`, expectErr: true},
+		{name: "missing expires_in", body: `{"access_token":"at","refresh_token":"rt"}`, expectErr: true},
+		{name: "invalid expires_in", body: `{"access_token":"at","expires_in":"soon"}`, expectErr: true},
+		{name: "empty object", body: `{}`, expectErr: true},
+		{name: "not JSON", body: `<html>Bad Gateway</html>`, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		token, err := decodeTokenResponse([]byte(tt.body))
+		switch {
+		case tt.oauthErr != nil:
+			var oauthErr *OAuthError
+			if assert.True(t, errors.As(err, &oauthErr), tt.name) {
+				assert.Equal(t, tt.oauthErr, oauthErr, tt.name)
+			}
+		case tt.expectErr:
+			assert.Error(t, err, tt.name)
+			var oauthErr *OAuthError
+			assert.False(t, errors.As(err, &oauthErr), tt.name)
+		default:
+			assert.NoError(t, err, tt.name)
+			assert.Equal(t, tt.expected, token, tt.name)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/ducminhgd/zalo-go-sdk/x/atomicfile"
+)
+
+// ErrTokenNotFound is returned by a TokenStore that holds no token yet.
+var ErrTokenNotFound = errors.New("zalo: token not found")
+
+// TokenStore persists the access token of an Official Account.
+// Implementations must be safe for concurrent use.
+type TokenStore interface {
+	// LoadToken returns the stored token, or ErrTokenNotFound if there is none.
+	LoadToken(ctx context.Context) (AccessToken, error)
+	// SaveToken replaces the stored token.
+	SaveToken(ctx context.Context, token AccessToken) error
+}
+
+// MemoryTokenStore is a TokenStore keeping the token in memory.
+type MemoryTokenStore struct {
+	mu    sync.Mutex
+	token AccessToken
+}
+
+func (s *MemoryTokenStore) LoadToken(ctx context.Context) (AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.AccessToken == "" {
+		return AccessToken{}, ErrTokenNotFound
+	}
+	return s.token, nil
+}
+
+func (s *MemoryTokenStore) SaveToken(ctx context.Context, token AccessToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = token
+	return nil
+}
+
+// FileTokenStore is a TokenStore keeping the token as JSON in a file.
+// The file is replaced atomically on save.
+type FileTokenStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *FileTokenStore) LoadToken(ctx context.Context) (AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var token AccessToken
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return token, ErrTokenNotFound
+	}
+	if err != nil {
+		return token, err
+	}
+	err = json.Unmarshal(data, &token)
+	return token, err
+}
+
+func (s *FileTokenStore) SaveToken(ctx context.Context, token AccessToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.Path, data, 0o600)
+}
@@ -0,0 +1,32 @@
+package client
+
+import "fmt"
+
+// ZnsError is a ZNS response with an error code other than SUCCESS.
+//
+// Sentinel ZnsErrors such as ErrJourneyTokenExpired match any ZnsError with the same code
+// with errors.Is:
+//
+//	if errors.Is(response.Err(), client.ErrJourneyTokenExpired) { ... }
+type ZnsError struct {
+	Code    int
+	Message string
+}
+
+func (e *ZnsError) Error() string {
+	return fmt.Sprintf("zalo: error code %d: %s", e.Code, e.Message)
+}
+
+// Is reports whether target is a ZnsError with the same code.
+func (e *ZnsError) Is(target error) bool {
+	t, ok := target.(*ZnsError)
+	return ok && t.Code == e.Code
+}
+
+// Err returns the error of a send response as a *ZnsError, or nil if the message was sent.
+func (r ZnsSendMsgReponse) Err() error {
+	if r.Error == SUCCESS {
+		return nil
+	}
+	return &ZnsError{Code: r.Error, Message: r.Message}
+}
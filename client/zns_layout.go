@@ -0,0 +1,122 @@
+package client
+
+import "regexp"
+
+// Types of template buttons, see ZnsTplButton.
+const (
+	ZNS_TPL_BUTTON_URL           = 1 // Opens the URL in Content
+	ZNS_TPL_BUTTON_CALL          = 2 // Calls the phone number in Content
+	ZNS_TPL_BUTTON_OA            = 3 // Opens the OA page
+	ZNS_TPL_BUTTON_MINI_APP      = 4 // Opens the Zalo mini app in Content
+	ZNS_TPL_BUTTON_DOWNLOAD_APP  = 5 // Opens the app download page in Content
+	ZNS_TPL_BUTTON_PRODUCT       = 6 // Opens the product page in Content
+	ZNS_TPL_BUTTON_OTHER_WEBSITE = 7 // Opens the website in Content
+	ZNS_TPL_BUTTON_OTHER_APP     = 8 // Opens the app in Content
+)
+
+// ZnsTplLayout is the content of a template: a header with the logo, a body with the title,
+// paragraphs and tables, and a footer with the buttons.
+//
+// Texts refer to the template parameters as <name>, e.g. "Xin chào <customer_name>".
+type ZnsTplLayout struct {
+	Header ZnsTplLayoutSection `json:"header"`
+	Body   ZnsTplLayoutSection `json:"body"`
+	Footer ZnsTplLayoutSection `json:"footer"`
+}
+
+type ZnsTplLayoutSection struct {
+	Components []ZnsTplComponent `json:"components"`
+}
+
+// ZnsTplComponent is a component of a layout section. Exactly one field is set.
+type ZnsTplComponent struct {
+	Logo      *ZnsTplLogo    `json:"LOGO,omitempty"`
+	Title     *ZnsTplText    `json:"TITLE,omitempty"`
+	Paragraph *ZnsTplText    `json:"PARAGRAPH,omitempty"`
+	Table     *ZnsTplTable   `json:"TABLE,omitempty"`
+	Buttons   *ZnsTplButtons `json:"BUTTONS,omitempty"`
+}
+
+type ZnsTplText struct {
+	Value string `json:"value"`
+}
+
+type ZnsTplLogo struct {
+	Light ZnsTplMedia `json:"light"`
+	Dark  ZnsTplMedia `json:"dark"`
+}
+
+type ZnsTplMedia struct {
+	Type    string `json:"type"` // IMAGE
+	MediaID string `json:"media_id"`
+}
+
+type ZnsTplTable struct {
+	Rows []ZnsTplTableRow `json:"rows"`
+}
+
+type ZnsTplTableRow struct {
+	Title   string `json:"title"`
+	Value   string `json:"value"`
+	RowType int    `json:"row_type,omitempty"`
+}
+
+type ZnsTplButtons struct {
+	Items []ZnsTplButton `json:"items"`
+}
+
+type ZnsTplButton struct {
+	Type    int    `json:"type"` // One of the ZNS_TPL_BUTTON_* constants
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// layoutParam matches a parameter in a layout text.
+var layoutParam = regexp.MustCompile(`<([A-Za-z0-9_]+)>`)
+
+// Params returns the names of the parameters used in the texts of the layout, in order of first use.
+func (l ZnsTplLayout) Params() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, text := range l.Texts() {
+		for _, match := range layoutParam.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// Texts returns the texts of the layout that may use parameters, in order:
+// titles, paragraphs, table row titles and values, button titles and contents.
+func (l ZnsTplLayout) Texts() []string {
+	var texts []string
+	for _, section := range []ZnsTplLayoutSection{l.Header, l.Body, l.Footer} {
+		for _, c := range section.Components {
+			switch {
+			case c.Title != nil:
+				texts = append(texts, c.Title.Value)
+			case c.Paragraph != nil:
+				texts = append(texts, c.Paragraph.Value)
+			case c.Table != nil:
+				for _, row := range c.Table.Rows {
+					texts = append(texts, row.Title, row.Value)
+				}
+			case c.Buttons != nil:
+				for _, button := range c.Buttons.Items {
+					texts = append(texts, button.Title, button.Content)
+				}
+			}
+		}
+	}
+	return texts
+}
+
+// ReplaceParams returns text with each parameter replaced by the result of replace.
+func ReplaceParams(text string, replace func(name string) string) string {
+	return layoutParam.ReplaceAllStringFunc(text, func(match string) string {
+		return replace(match[1 : len(match)-1])
+	})
+}
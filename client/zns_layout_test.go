@@ -0,0 +1,35 @@
+package client_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+func TestZnsTplLayout(t *testing.T) {
+	body := `{
+		"header": {"components": [{"LOGO": {"light": {"type": "IMAGE", "media_id": "m1"}, "dark": {"type": "IMAGE", "media_id": "m2"}}}]},
+		"body": {"components": [
+			{"TITLE": {"value": "Đơn hàng <order_code>"}},
+			{"PARAGRAPH": {"value": "Chào <customer_name>, đơn <order_code> đã được xác nhận."}},
+			{"TABLE": {"rows": [{"title": "Tổng tiền", "value": "<cost>", "row_type": 1}]}}
+		]},
+		"footer": {"components": [{"BUTTONS": {"items": [{"type": 1, "title": "Xem", "content": "https://example.com/<order_code>?ref=<ref>"}]}}]}
+	}`
+
+	var layout client.ZnsTplLayout
+	require.NoError(t, json.Unmarshal([]byte(body), &layout))
+	require.NotNil(t, layout.Header.Components[0].Logo)
+	assert.Equal(t, "m2", layout.Header.Components[0].Logo.Dark.MediaID)
+	assert.Equal(t, client.ZNS_TPL_BUTTON_URL, layout.Footer.Components[0].Buttons.Items[0].Type)
+	assert.Equal(t, []string{"order_code", "customer_name", "cost", "ref"}, layout.Params())
+
+	text := client.ReplaceParams(layout.Body.Components[1].Paragraph.Value, func(name string) string {
+		return "{" + name + "}"
+	})
+	assert.Equal(t, "Chào {customer_name}, đơn {order_code} đã được xác nhận.", text)
+}
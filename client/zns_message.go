@@ -4,10 +4,29 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/x/phone"
+)
+
+// SendMode is the mode a ZNS message is sent in.
+type SendMode string
+
+const (
+	SEND_MODE_DEFAULT     SendMode = ""            // Production mode, or the client default set with UseDevelopmentMode
+	SEND_MODE_DEVELOPMENT SendMode = "development" // Sent to the admins of the OA only, with the development quota
+)
+
+// SendingMode is the billing mode of a sent ZNS message, returned by Zalo.
+type SendingMode string
+
+const (
+	SENDING_MODE_NORMAL       SendingMode = "1" // Counted in the daily quota
+	SENDING_MODE_EXCEED_QUOTA SendingMode = "3" // Sent beyond the daily quota
 )
 
 type ZnsSendMsgRequest struct {
@@ -15,12 +34,14 @@ type ZnsSendMsgRequest struct {
 	TemplateID   string            `json:"template_id"`
 	TemplateData map[string]string `json:"template_data"` // This base on each template
 	TrackingID   string            `json:"tracking_id"`
+	Mode         SendMode          `json:"mode,omitempty"`
+	JourneyToken string            `json:"journey_token,omitempty"` // Set by SendZnsJourneyMessage
 }
 
 type ZnsSendMsgResponseData struct {
-	MsgID       string    `json:"msg_id"`
-	SentTime    time.Time `json:"sent_time"`
-	SendingMode string    `json:"sending_mode"`
+	MsgID       string      `json:"msg_id"`
+	SentTime    time.Time   `json:"sent_time"`
+	SendingMode SendingMode `json:"sending_mode"`
 	Quota       struct {
 		DailyQuota     string `json:"dailyQuota"`
 		RemainingQuota string `json:"remainingQuota"`
@@ -33,7 +54,92 @@ type ZnsSendMsgReponse struct {
 	Data    ZnsSendMsgResponseData `json:"data"`
 }
 
+// SendZnsMessage sends a ZNS message.
+//
+// The phone number is normalized to the 84XXXXXXXXX form unless disabled with UsePhoneNormalization.
+// A phone number that is not a Vietnamese mobile number is not sent and gets a PHONE_NUMBER_INVALID
+// response describing the problem, as Zalo would return.
+//
+// A request without Mode is sent in development mode if enabled with UseDevelopmentMode.
+//
+// If the request has no tracking ID, a unique one is generated with NewTrackingID.
+// If an idempotency store is set with UseIdempotencyStore, a request whose tracking ID
+// was already sent successfully returns the original response without sending again.
+//
+// E2EE templates are refused with ErrE2EETemplate: they must be sent with SendZnsE2EEMessage.
+// To tell, the first send of a template unknown to the client makes an extra template detail
+// request; if it fails, the message is not sent and an error wrapping the failure, e.g. a
+// *ZnsError, is returned.
+//
+// If a spend tracker is set with UseSpendTracker, a message that would exceed its budget is
+// refused with an error wrapping ErrBudgetExceeded. The price of a template unknown to the
+// tracker is got from the template detail first; if that fails, the message is not sent and
+// an error wrapping the failure, e.g. a *ZnsError, is returned.
 func (z *ZaloClient) SendZnsMessage(ctx context.Context, request ZnsSendMsgRequest) (ZnsSendMsgReponse, error) {
+	e2ee, err := z.lookupE2EETemplate(ctx, request.TemplateID)
+	if err != nil {
+		// Fail closed: the template may be an E2EE template
+		return ZnsSendMsgReponse{}, err
+	}
+	if e2ee {
+		return ZnsSendMsgReponse{}, ErrE2EETemplate
+	}
+	return z.sendMessage(ctx, ENDPOINT_MESSAGE_SEND, request)
+}
+
+// sendMessage applies the request defaults of SendZnsMessage and sends the request to endpoint.
+func (z *ZaloClient) sendMessage(ctx context.Context, endpoint string, request ZnsSendMsgRequest) (ZnsSendMsgReponse, error) {
+	if !z.rawPhone {
+		number, err := phone.Normalize(request.Phone)
+		if err != nil {
+			return ZnsSendMsgReponse{Error: PHONE_NUMBER_INVALID, Message: err.Error()}, nil
+		}
+		request.Phone = number
+	}
+	if request.Mode == SEND_MODE_DEFAULT && z.devMode {
+		request.Mode = SEND_MODE_DEVELOPMENT
+	}
+	if request.TrackingID == "" {
+		request.TrackingID = NewTrackingID()
+	}
+	return z.sendIdempotent(ctx, request.TrackingID, func() (ZnsSendMsgReponse, error) {
+		return z.sendBudgeted(ctx, endpoint, request)
+	})
+}
+
+// sendBudgeted sends a request within the budget of the spend tracker, if any.
+// An error getting the price is returned wrapped, the message is not sent.
+func (z *ZaloClient) sendBudgeted(ctx context.Context, endpoint string, request ZnsSendMsgRequest) (ZnsSendMsgReponse, error) {
+	tracker := z.GetSpendTracker()
+	if tracker == nil || request.Mode == SEND_MODE_DEVELOPMENT {
+		return z.sendZnsMessage(ctx, endpoint, request)
+	}
+
+	price, err := z.templatePrice(ctx, request.TemplateID)
+	if err != nil {
+		return ZnsSendMsgReponse{}, fmt.Errorf("zalo: getting price of template %s: %w", request.TemplateID, err)
+	}
+	if err := tracker.reserve(price); err != nil {
+		return ZnsSendMsgReponse{}, err
+	}
+	response, err := z.sendZnsMessage(ctx, endpoint, request)
+	tracker.settle(price, err == nil && response.Error == SUCCESS)
+	return response, err
+}
+
+// UsePhoneNormalization enables or disables the normalization of phone numbers by the send methods.
+// It is enabled by default.
+func (z *ZaloClient) UsePhoneNormalization(enabled bool) {
+	z.rawPhone = !enabled
+}
+
+// UseDevelopmentMode makes requests without Mode be sent in development mode, e.g. in staging
+// environments: messages are only delivered to the admins of the OA and do not use the production quota.
+func (z *ZaloClient) UseDevelopmentMode(enabled bool) {
+	z.devMode = enabled
+}
+
+func (z *ZaloClient) sendZnsMessage(ctx context.Context, endpoint string, request ZnsSendMsgRequest) (ZnsSendMsgReponse, error) {
 	var response ZnsSendMsgReponse
 
 	// Set up the request body as a JSON object
@@ -43,15 +149,15 @@ func (z *ZaloClient) SendZnsMessage(ctx context.Context, request ZnsSendMsgReque
 		return response, err
 	}
 
-	req, err := http.NewRequest("POST", ENDPOINT_MESSAGE_SEND, bytes.NewReader(jsonBytes))
+	req, err := http.NewRequest("POST", z.endpoint(endpoint), bytes.NewReader(jsonBytes))
 	if err != nil {
 		z.GetLogger().ErrorContext(ctx, "Error creating request:", slog.Any("err", err))
 		return response, err
 	}
 	// Set headers
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("access_token", z.token.AccessToken)
-	resp, err := z.GetHTTPClient().Do(req)
+	req.Header.Add("access_token", z.GetAccessToken().AccessToken)
+	resp, err := z.do(ctx, ENDPOINT_GROUP_SEND, req)
 	if err != nil {
 		z.GetLogger().ErrorContext(ctx, "Error sending request:", slog.Any("err", err))
 		return response, err
@@ -69,5 +175,9 @@ func (z *ZaloClient) SendZnsMessage(ctx context.Context, request ZnsSendMsgReque
 		z.GetLogger().ErrorContext(ctx, "Error unmarshaling response:", slog.Any("err", err))
 		return response, err
 	}
+	// Development sends use a separate quota
+	if tracker := z.GetQuotaTracker(); tracker != nil && request.Mode != SEND_MODE_DEVELOPMENT {
+		tracker.Record(response)
+	}
 	return response, nil
 }
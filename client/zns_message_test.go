@@ -0,0 +1,195 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func newSendTestServer() *zalotest.Server {
+	srv := zalotest.NewServer()
+	srv.AddTemplate(client.ZnsTplDetailData{
+		TemplateID: 100,
+		Status:     client.ZNS_TPL_STATUS_ENABLED_NAME,
+		ListParams: []client.ZnsTplDetailParam{
+			{Name: "cost", Require: true, Type: "NUMBER", MaxLength: 20},
+			{Name: "note", Require: false, Type: "STRING", MaxLength: 10},
+		},
+	})
+	return srv
+}
+
+func TestSendZnsMessage(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	srv.SetQuota(500, 2)
+
+	zc := srv.Client()
+	ctx := context.Background()
+
+	response, err := zc.SendZnsMessage(ctx, client.ZnsSendMsgRequest{
+		Phone:        "84987654321",
+		TemplateID:   "100",
+		TemplateData: map[string]string{"cost": "10,000"},
+		TrackingID:   "tracking-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+	assert.NotEmpty(t, response.Data.MsgID)
+	assert.Equal(t, "1", response.Data.Quota.RemainingQuota)
+
+	messages := srv.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, response.Data.MsgID, messages[0].MsgID)
+	assert.Equal(t, "tracking-1", messages[0].TrackingID)
+	assert.Equal(t, map[string]string{"cost": "10,000"}, messages[0].TemplateData)
+}
+
+func TestSendZnsMessagePhoneNormalization(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+
+	zc := srv.Client()
+	ctx := context.Background()
+	request := client.ZnsSendMsgRequest{TemplateID: "100", TemplateData: map[string]string{"cost": "1"}}
+
+	for _, phone := range []string{"0987 654 321", "+84987654321", "01681234567"} {
+		request.Phone = phone
+		response, err := zc.SendZnsMessage(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, client.SUCCESS, response.Error, phone)
+	}
+	messages := srv.Messages()
+	require.Len(t, messages, 3)
+	assert.Equal(t, "84987654321", messages[0].Phone)
+	assert.Equal(t, "84987654321", messages[1].Phone)
+	assert.Equal(t, "84381234567", messages[2].Phone)
+
+	request.Phone = "+65 9123 4567"
+	response, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.PHONE_NUMBER_INVALID, response.Error)
+	assert.Contains(t, response.Message, "not a Vietnamese phone number")
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_MESSAGE_SEND), 3, "invalid phone numbers are not sent")
+
+	zc.UsePhoneNormalization(false)
+	request.Phone = "0987 654 321"
+	response, err = zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.PHONE_NUMBER_INVALID, response.Error, "the phone number is sent verbatim")
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_MESSAGE_SEND), 4)
+}
+
+func TestSendZnsMessageDevelopmentMode(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	srv.AddAdmin("84987654321")
+	srv.SetDevQuota(1)
+
+	zc := srv.Client()
+	ctx := context.Background()
+	request := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "100", TemplateData: map[string]string{"cost": "1"}}
+
+	zc.UseDevelopmentMode(true)
+	response, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+	assert.Equal(t, client.SENDING_MODE_NORMAL, response.Data.SendingMode)
+
+	response, err = zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.OUT_OF_QUOTA_DEV, response.Error)
+
+	request.Phone = "84912345678"
+	response, err = zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.TEST_MESSAGE_SENT_TO_ADMIN_ONLY, response.Error)
+
+	zc.UseDevelopmentMode(false)
+	response, err = zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+
+	// Per request, without the client default
+	request.Mode = client.SEND_MODE_DEVELOPMENT
+	request.Phone = "84987654321"
+	srv.SetDevQuota(1)
+	response, err = zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+
+	messages := srv.Messages()
+	require.Len(t, messages, 3)
+	assert.Equal(t, client.SEND_MODE_DEVELOPMENT, messages[0].Mode)
+	assert.Equal(t, client.SEND_MODE_DEFAULT, messages[1].Mode)
+	assert.Equal(t, client.SEND_MODE_DEVELOPMENT, messages[2].Mode)
+	_, remaining := srv.Quota()
+	assert.Equal(t, 499, remaining, "development sends do not use the production quota")
+}
+
+func TestSendZnsMessageErrors(t *testing.T) {
+	valid := client.ZnsSendMsgRequest{
+		Phone:        "84987654321",
+		TemplateID:   "100",
+		TemplateData: map[string]string{"cost": "10,000"},
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(r *client.ZnsSendMsgRequest)
+		inject int
+		want   int
+	}{
+		{"invalid phone", func(r *client.ZnsSendMsgRequest) { r.Phone = "024 3825 1234" }, 0, client.PHONE_NUMBER_INVALID},
+		{"missing parameter", func(r *client.ZnsSendMsgRequest) { r.TemplateData = map[string]string{} }, 0, client.TEMPLATE_DATA_MISSING_PARAMETER_NAME},
+		{"too long parameter", func(r *client.ZnsSendMsgRequest) {
+			r.TemplateData = map[string]string{"cost": "1", "note": "more than ten characters"}
+		}, 0, client.PARAMETER_NAME_DATA_BREAKS_LENGTH},
+		{"injected", func(r *client.ZnsSendMsgRequest) {}, client.OA_BLOCKED_SEND_ZNS_MESSAGE, client.OA_BLOCKED_SEND_ZNS_MESSAGE},
+	}
+
+	for _, tt := range tests {
+		srv := newSendTestServer()
+		zc := srv.Client()
+		if tt.inject != 0 {
+			srv.InjectError(client.ENDPOINT_MESSAGE_SEND, tt.inject)
+		}
+
+		request := valid
+		tt.mutate(&request)
+		response, err := zc.SendZnsMessage(context.Background(), request)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, response.Error, tt.name)
+		assert.Empty(t, srv.Messages(), tt.name)
+		srv.Close()
+	}
+
+	// The detail of an unknown template cannot be got to check whether it is an E2EE template
+	srv := newSendTestServer()
+	defer srv.Close()
+	request := valid
+	request.TemplateID = "101"
+	_, err := srv.Client().SendZnsMessage(context.Background(), request)
+	assert.ErrorIs(t, err, &client.ZnsError{Code: client.TEMPLATE_ID_INVALID}, "unknown template")
+	assert.Empty(t, srv.Messages(), "unknown template")
+}
+
+func TestSendZnsMessageOutOfQuota(t *testing.T) {
+	srv := newSendTestServer()
+	defer srv.Close()
+	srv.SetQuota(500, 0)
+
+	zc := srv.Client()
+	response, err := zc.SendZnsMessage(context.Background(), client.ZnsSendMsgRequest{
+		Phone:        "84987654321",
+		TemplateID:   "100",
+		TemplateData: map[string]string{"cost": "10,000"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, client.OUT_OF_QUOTA, response.Error)
+}
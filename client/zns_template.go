@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+
+	"github.com/ducminhgd/zalo-go-sdk/x/znsformat"
 )
 
 const (
@@ -25,6 +27,16 @@ const (
 	ZNS_TPL_STATUS_DISABLED_NAME       = "DISABLE"
 )
 
+// Types of template parameters, see ZnsTplDetailParam.
+const (
+	ZNS_TPL_PARAM_TYPE_STRING   = "STRING"
+	ZNS_TPL_PARAM_TYPE_NUMBER   = "NUMBER"
+	ZNS_TPL_PARAM_TYPE_DATE     = "DATE"
+	ZNS_TPL_PARAM_TYPE_CURRENCY = "CURRENCY"
+
+	ZNS_TPL_DATE_LAYOUT = znsformat.DATE_LAYOUT // Layout of DATE parameter values, for time.Format
+)
+
 type ZnsTplListRequest struct {
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
@@ -76,7 +88,7 @@ func (z *ZaloClient) GetZnsTemplateList(ctx context.Context, request ZnsTplListR
 	}
 
 	// Create the request URL with the query string parameters
-	reqUrl := fmt.Sprintf("%s?%s", ENDPOINT_TEMPLATE_LIST, query.Encode())
+	reqUrl := fmt.Sprintf("%s?%s", z.endpoint(ENDPOINT_TEMPLATE_LIST), query.Encode())
 
 	req, err := http.NewRequest("GET", reqUrl, nil)
 	if err != nil {
@@ -86,8 +98,8 @@ func (z *ZaloClient) GetZnsTemplateList(ctx context.Context, request ZnsTplListR
 
 	// Set headers
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("access_token", z.token.AccessToken)
-	resp, err := z.GetHTTPClient().Do(req)
+	req.Header.Add("access_token", z.GetAccessToken().AccessToken)
+	resp, err := z.do(ctx, ENDPOINT_GROUP_TEMPLATE, req)
 	if err != nil {
 		z.GetLogger().ErrorContext(ctx, "Error sending request:", slog.Any("err", err))
 		return response, err
@@ -129,6 +141,8 @@ type ZnsTplDetailData struct {
 	TemplateQuality string              `json:"templateQuality"`
 	TemplateTag     string              `json:"templateTag"`
 	Price           string              `json:"price"`
+	E2EE            bool                `json:"isE2EE"`           // Template data must be encrypted, see SendZnsE2EEMessage
+	Layout          *ZnsTplLayout       `json:"layout,omitempty"` // Content of the template, when returned by Zalo
 }
 
 type ZnsTplDetailResponse struct {
@@ -151,7 +165,7 @@ func (z *ZaloClient) GetZnsTemplateDetail(ctx context.Context, templateID string
 	query.Set("template_id", templateID)
 
 	// Create the request URL with the query string parameters
-	reqUrl := fmt.Sprintf("%s?%s", ENDPOINT_TEMPLATE_DETAIL, query.Encode())
+	reqUrl := fmt.Sprintf("%s?%s", z.endpoint(ENDPOINT_TEMPLATE_DETAIL), query.Encode())
 
 	req, err := http.NewRequest("GET", reqUrl, nil)
 	if err != nil {
@@ -161,8 +175,8 @@ func (z *ZaloClient) GetZnsTemplateDetail(ctx context.Context, templateID string
 
 	// Set headers
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("access_token", z.token.AccessToken)
-	resp, err := z.GetHTTPClient().Do(req)
+	req.Header.Add("access_token", z.GetAccessToken().AccessToken)
+	resp, err := z.do(ctx, ENDPOINT_GROUP_TEMPLATE, req)
 	if err != nil {
 		z.GetLogger().ErrorContext(ctx, "Error sending request:", slog.Any("err", err))
 		return response, err
@@ -181,6 +195,14 @@ func (z *ZaloClient) GetZnsTemplateDetail(ctx context.Context, templateID string
 		z.GetLogger().ErrorContext(ctx, "Error unmarshalling response:", slog.Any("err", err))
 		return response, err
 	}
+	if response.Error == SUCCESS {
+		z.recordTemplate(templateID, response.Data.E2EE)
+		if tracker := z.GetSpendTracker(); tracker != nil {
+			if price, err := response.Data.ParsePrice(); err == nil {
+				tracker.SetPrice(templateID, price)
+			}
+		}
+	}
 
 	return response, nil
 }
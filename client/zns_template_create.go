@@ -0,0 +1,378 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Errors of the template creation API, to be matched with errors.Is, e.g. on response.Err().
+// Like every *ZnsError, they match any error with the same code, whatever the API returning it.
+var (
+	// ErrNoPermissionCreateTemplate matches OA_NO_PERMISSION_CREATE_TEMPLATE: the OA may not create templates.
+	ErrNoPermissionCreateTemplate = &ZnsError{Code: OA_NO_PERMISSION_CREATE_TEMPLATE, Message: "OA has no permission to create templates"}
+	// ErrNoPermissionUseResource matches OA_NO_PERMISSION_USE_RESOURCE: the OA may not use a resource of the request, e.g. a logo.
+	ErrNoPermissionUseResource = &ZnsError{Code: OA_NO_PERMISSION_USE_RESOURCE, Message: "OA has no permission to use the resource"}
+	// ErrInvalidParameter matches INVALID_PARAMETER, e.g. a template request rejected by ZnsTplCreateRequest.Validate.
+	ErrInvalidParameter = &ZnsError{Code: INVALID_PARAMETER, Message: "Request has an invalid parameter"}
+	// ErrButtonInvalid matches BUTTON_INVALID, e.g. a template button with an invalid content.
+	ErrButtonInvalid = &ZnsError{Code: BUTTON_INVALID, Message: "Button is invalid"}
+	// ErrDataInvalid matches DATA_INVALID, e.g. a template layout or parameter rejected by Zalo.
+	ErrDataInvalid = &ZnsError{Code: DATA_INVALID, Message: "Data is invalid"}
+)
+
+// ZnsTplType is the type of a template.
+type ZnsTplType int
+
+const (
+	ZNS_TPL_TYPE_CUSTOM          ZnsTplType = 1
+	ZNS_TPL_TYPE_AUTHENTICATION  ZnsTplType = 2
+	ZNS_TPL_TYPE_PAYMENT_REQUEST ZnsTplType = 3
+	ZNS_TPL_TYPE_VOUCHER         ZnsTplType = 4
+	ZNS_TPL_TYPE_RATING          ZnsTplType = 5
+)
+
+// ZnsTplTag is the content category of a template.
+type ZnsTplTag string
+
+const (
+	ZNS_TPL_TAG_TRANSACTION   ZnsTplTag = "1"
+	ZNS_TPL_TAG_CUSTOMER_CARE ZnsTplTag = "2"
+	ZNS_TPL_TAG_PROMOTION     ZnsTplTag = "3"
+)
+
+// ZnsTplParamType is the type of a parameter of a template to create. It sets the maximum length of its values.
+type ZnsTplParamType string
+
+const (
+	ZNS_TPL_CREATE_PARAM_CUSTOMER_NAME      ZnsTplParamType = "1"
+	ZNS_TPL_CREATE_PARAM_PHONE_NUMBER       ZnsTplParamType = "2"
+	ZNS_TPL_CREATE_PARAM_ADDRESS            ZnsTplParamType = "3"
+	ZNS_TPL_CREATE_PARAM_CODE               ZnsTplParamType = "4" // e.g. an order or customer code
+	ZNS_TPL_CREATE_PARAM_CUSTOM_LABEL       ZnsTplParamType = "5"
+	ZNS_TPL_CREATE_PARAM_TRANSACTION_STATUS ZnsTplParamType = "6"
+	ZNS_TPL_CREATE_PARAM_CONTACT            ZnsTplParamType = "7"
+	ZNS_TPL_CREATE_PARAM_GENDER             ZnsTplParamType = "8"
+	ZNS_TPL_CREATE_PARAM_PRODUCT_NAME       ZnsTplParamType = "9"
+	ZNS_TPL_CREATE_PARAM_QUANTITY           ZnsTplParamType = "10"
+	ZNS_TPL_CREATE_PARAM_TIME               ZnsTplParamType = "11"
+	ZNS_TPL_CREATE_PARAM_OTP                ZnsTplParamType = "12"
+	ZNS_TPL_CREATE_PARAM_URL                ZnsTplParamType = "13"
+	ZNS_TPL_CREATE_PARAM_CURRENCY           ZnsTplParamType = "14" // Amount in VND
+	ZNS_TPL_CREATE_PARAM_BANK_TRANSFER_NOTE ZnsTplParamType = "15"
+)
+
+// paramMaxLengths are the maximum lengths of the values of each parameter type.
+var paramMaxLengths = map[ZnsTplParamType]int{
+	ZNS_TPL_CREATE_PARAM_CUSTOMER_NAME:      30,
+	ZNS_TPL_CREATE_PARAM_PHONE_NUMBER:       15,
+	ZNS_TPL_CREATE_PARAM_ADDRESS:            200,
+	ZNS_TPL_CREATE_PARAM_CODE:               30,
+	ZNS_TPL_CREATE_PARAM_CUSTOM_LABEL:       30,
+	ZNS_TPL_CREATE_PARAM_TRANSACTION_STATUS: 30,
+	ZNS_TPL_CREATE_PARAM_CONTACT:            50,
+	ZNS_TPL_CREATE_PARAM_GENDER:             5,
+	ZNS_TPL_CREATE_PARAM_PRODUCT_NAME:       200,
+	ZNS_TPL_CREATE_PARAM_QUANTITY:           20,
+	ZNS_TPL_CREATE_PARAM_TIME:               20,
+	ZNS_TPL_CREATE_PARAM_OTP:                10,
+	ZNS_TPL_CREATE_PARAM_URL:                200,
+	ZNS_TPL_CREATE_PARAM_CURRENCY:           12,
+	ZNS_TPL_CREATE_PARAM_BANK_TRANSFER_NOTE: 90,
+}
+
+// MaxLength returns the maximum length of the values of the parameter type, or 0 for an unknown type.
+func (t ZnsTplParamType) MaxLength() int {
+	return paramMaxLengths[t]
+}
+
+// Limits of the templates to create, checked by ZnsTplCreateRequest.Validate.
+const (
+	ZNS_TPL_NAME_MIN_LENGTH      = 10
+	ZNS_TPL_NAME_MAX_LENGTH      = 60
+	ZNS_TPL_NOTE_MAX_LENGTH      = 400
+	ZNS_TPL_TITLE_MAX_LENGTH     = 65
+	ZNS_TPL_PARAGRAPH_MAX_LENGTH = 400
+	ZNS_TPL_MAX_PARAGRAPHS       = 4
+	ZNS_TPL_MAX_TABLE_ROWS       = 8
+	ZNS_TPL_ROW_TITLE_MAX_LENGTH = 25
+	ZNS_TPL_ROW_VALUE_MAX_LENGTH = 100
+	ZNS_TPL_MAX_BUTTONS          = 2
+	ZNS_TPL_BUTTON_MAX_LENGTH    = 30
+)
+
+type ZnsTplCreateParam struct {
+	Type        ZnsTplParamType `json:"type"`
+	Name        string          `json:"name"`
+	SampleValue string          `json:"sample_value"` // Shown to the Zalo reviewers
+}
+
+type ZnsTplCreateRequest struct {
+	TemplateName string              `json:"template_name"`
+	TemplateType ZnsTplType          `json:"template_type"`
+	Tag          ZnsTplTag           `json:"tag"`
+	Layout       ZnsTplLayout        `json:"layout"`
+	Params       []ZnsTplCreateParam `json:"params"`
+	Note         string              `json:"note,omitempty"` // Note to the Zalo reviewers
+	TrackingID   string              `json:"tracking_id"`
+}
+
+type ZnsTplCreateData struct {
+	TemplateID     string     `json:"template_id"`
+	TemplateName   string     `json:"template_name"`
+	TemplateType   ZnsTplType `json:"template_type"`
+	TemplateStatus string     `json:"template_status"`
+	Tag            ZnsTplTag  `json:"tag"`
+	Price          string     `json:"price"`
+	PreviewURL     string     `json:"preview_url"`
+}
+
+type ZnsTplCreateResponse struct {
+	Error   int              `json:"error"`
+	Message string           `json:"message"`
+	Data    ZnsTplCreateData `json:"data"`
+}
+
+// Err returns the error of a create response as a *ZnsError, or nil if the template was created.
+// Errors can be told apart with errors.Is, e.g. errors.Is(response.Err(), ErrNoPermissionCreateTemplate).
+func (r ZnsTplCreateResponse) Err() error {
+	if r.Error == SUCCESS {
+		return nil
+	}
+	return &ZnsError{Code: r.Error, Message: r.Message}
+}
+
+// templateParamName matches the names of template parameters.
+var templateParamName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Validate checks the request against the constraints of Zalo on templates, so that mistakes are
+// caught before submission. It returns nil, or a *ZnsError matching ErrInvalidParameter
+// describing the first problem found.
+//
+// Every parameter must be used in the layout, every parameter used in the layout must be defined,
+// and sample values must fit the maximum length of their parameter type.
+func (r ZnsTplCreateRequest) Validate() error {
+	invalid := func(format string, args ...any) error {
+		return &ZnsError{Code: INVALID_PARAMETER, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if n := utf8.RuneCountInString(r.TemplateName); n < ZNS_TPL_NAME_MIN_LENGTH || n > ZNS_TPL_NAME_MAX_LENGTH {
+		return invalid("template name must have %d to %d characters, got %d", ZNS_TPL_NAME_MIN_LENGTH, ZNS_TPL_NAME_MAX_LENGTH, n)
+	}
+	if r.TemplateType < ZNS_TPL_TYPE_CUSTOM || r.TemplateType > ZNS_TPL_TYPE_RATING {
+		return invalid("template type %d is unknown", r.TemplateType)
+	}
+	switch r.Tag {
+	case ZNS_TPL_TAG_TRANSACTION, ZNS_TPL_TAG_CUSTOMER_CARE, ZNS_TPL_TAG_PROMOTION:
+	default:
+		return invalid("template tag %q is unknown", r.Tag)
+	}
+	if r.TrackingID == "" {
+		return invalid("tracking ID is required")
+	}
+	if n := utf8.RuneCountInString(r.Note); n > ZNS_TPL_NOTE_MAX_LENGTH {
+		return invalid("note must have up to %d characters, got %d", ZNS_TPL_NOTE_MAX_LENGTH, n)
+	}
+	if err := validateLayout(r.Layout); err != nil {
+		return invalid("%s", err)
+	}
+
+	defined := make(map[string]bool, len(r.Params))
+	for _, param := range r.Params {
+		switch {
+		case !templateParamName.MatchString(param.Name):
+			return invalid("parameter name %q must only have letters, digits and underscores", param.Name)
+		case defined[param.Name]:
+			return invalid("parameter %s is defined twice", param.Name)
+		case param.Type.MaxLength() == 0:
+			return invalid("parameter %s has unknown type %q", param.Name, param.Type)
+		case param.SampleValue == "":
+			return invalid("parameter %s has no sample value", param.Name)
+		}
+		if n := utf8.RuneCountInString(param.SampleValue); n > param.Type.MaxLength() {
+			return invalid("sample value of parameter %s must have up to %d characters, got %d", param.Name, param.Type.MaxLength(), n)
+		}
+		defined[param.Name] = true
+	}
+
+	used := r.Layout.Params()
+	for _, name := range used {
+		if !defined[name] {
+			return invalid("parameter %s is used in the layout but not defined", name)
+		}
+		delete(defined, name)
+	}
+	if len(defined) > 0 {
+		unused := make([]string, 0, len(defined))
+		for name := range defined {
+			unused = append(unused, name)
+		}
+		sort.Strings(unused)
+		return invalid("parameters %s are defined but not used in the layout", strings.Join(unused, ", "))
+	}
+	return nil
+}
+
+// validateLayout checks the components of a layout.
+func validateLayout(layout ZnsTplLayout) error {
+	var logos, titles, paragraphs, tables, buttons int
+	for _, section := range []struct {
+		name       string
+		components []ZnsTplComponent
+	}{
+		{"header", layout.Header.Components},
+		{"body", layout.Body.Components},
+		{"footer", layout.Footer.Components},
+	} {
+		for i, c := range section.components {
+			set := 0
+			for _, field := range []bool{c.Logo != nil, c.Title != nil, c.Paragraph != nil, c.Table != nil, c.Buttons != nil} {
+				if field {
+					set++
+				}
+			}
+			if set != 1 {
+				return fmt.Errorf("%s component %d must have exactly one content, got %d", section.name, i+1, set)
+			}
+
+			switch {
+			case c.Logo != nil:
+				if section.name != "header" {
+					return fmt.Errorf("logo must be in the header")
+				}
+				if c.Logo.Light.MediaID == "" || c.Logo.Dark.MediaID == "" {
+					return fmt.Errorf("logo must have a light and a dark media ID")
+				}
+				logos++
+			case c.Buttons != nil:
+				if section.name != "footer" {
+					return fmt.Errorf("buttons must be in the footer")
+				}
+				for _, button := range c.Buttons.Items {
+					if err := checkText("button title", button.Title, ZNS_TPL_BUTTON_MAX_LENGTH); err != nil {
+						return err
+					}
+					if button.Type < ZNS_TPL_BUTTON_URL || button.Type > ZNS_TPL_BUTTON_OTHER_APP {
+						return fmt.Errorf("button %q has unknown type %d", button.Title, button.Type)
+					}
+					if button.Content == "" && button.Type != ZNS_TPL_BUTTON_OA {
+						return fmt.Errorf("button %q has no content", button.Title)
+					}
+				}
+				buttons += len(c.Buttons.Items)
+			case section.name != "body":
+				return fmt.Errorf("%s component %d must be in the body", section.name, i+1)
+			case c.Title != nil:
+				if err := checkText("title", c.Title.Value, ZNS_TPL_TITLE_MAX_LENGTH); err != nil {
+					return err
+				}
+				titles++
+			case c.Paragraph != nil:
+				if err := checkText("paragraph", c.Paragraph.Value, ZNS_TPL_PARAGRAPH_MAX_LENGTH); err != nil {
+					return err
+				}
+				paragraphs++
+			case c.Table != nil:
+				if len(c.Table.Rows) == 0 || len(c.Table.Rows) > ZNS_TPL_MAX_TABLE_ROWS {
+					return fmt.Errorf("table must have 1 to %d rows, got %d", ZNS_TPL_MAX_TABLE_ROWS, len(c.Table.Rows))
+				}
+				for _, row := range c.Table.Rows {
+					if err := checkText("row title", row.Title, ZNS_TPL_ROW_TITLE_MAX_LENGTH); err != nil {
+						return err
+					}
+					if err := checkText("row value", row.Value, ZNS_TPL_ROW_VALUE_MAX_LENGTH); err != nil {
+						return err
+					}
+				}
+				tables++
+			}
+		}
+	}
+
+	switch {
+	case logos > 1:
+		return fmt.Errorf("layout must have at most one logo, got %d", logos)
+	case titles != 1:
+		return fmt.Errorf("layout must have exactly one title, got %d", titles)
+	case paragraphs > ZNS_TPL_MAX_PARAGRAPHS:
+		return fmt.Errorf("layout must have at most %d paragraphs, got %d", ZNS_TPL_MAX_PARAGRAPHS, paragraphs)
+	case tables > 1:
+		return fmt.Errorf("layout must have at most one table, got %d", tables)
+	case buttons > ZNS_TPL_MAX_BUTTONS:
+		return fmt.Errorf("layout must have at most %d buttons, got %d", ZNS_TPL_MAX_BUTTONS, buttons)
+	}
+	return nil
+}
+
+// checkText checks that a layout text is not empty and fits a maximum length.
+func checkText(name, text string, maxLength int) error {
+	n := utf8.RuneCountInString(text)
+	if n == 0 {
+		return fmt.Errorf("%s must not be empty", name)
+	}
+	if n > maxLength {
+		return fmt.Errorf("%s must have up to %d characters, got %d", name, maxLength, n)
+	}
+	return nil
+}
+
+// CreateZnsTemplate submits a ZNS template for review by Zalo. The created template is PENDING_REVIEW
+// until approved or rejected.
+//
+// The request is checked with Validate first: an invalid request is not submitted and gets an
+// INVALID_PARAMETER response describing the problem. Errors, e.g. an OA without permission to create
+// templates, can be told apart with errors.Is on response.Err(), e.g.
+// errors.Is(response.Err(), ErrNoPermissionCreateTemplate).
+func (z *ZaloClient) CreateZnsTemplate(ctx context.Context, request ZnsTplCreateRequest) (ZnsTplCreateResponse, error) {
+	var response ZnsTplCreateResponse
+
+	var invalid *ZnsError
+	if errors.As(request.Validate(), &invalid) {
+		return ZnsTplCreateResponse{Error: invalid.Code, Message: invalid.Message}, nil
+	}
+
+	// Set up the request body as a JSON object
+	jsonBytes, err := json.Marshal(request)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error encoding request as JSON:", slog.Any("err", err))
+		return response, err
+	}
+
+	req, err := http.NewRequest("POST", z.endpoint(ENDPOINT_TEMPLATE_CREATE), bytes.NewReader(jsonBytes))
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error creating request:", slog.Any("err", err))
+		return response, err
+	}
+	// Set headers
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("access_token", z.GetAccessToken().AccessToken)
+	resp, err := z.do(ctx, ENDPOINT_GROUP_TEMPLATE, req)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error sending request:", slog.Any("err", err))
+		return response, err
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error reading response:", slog.Any("err", err))
+		return response, err
+	}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		z.GetLogger().ErrorContext(ctx, "Error unmarshalling response:", slog.Any("err", err))
+		return response, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,134 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func newCreateRequest() client.ZnsTplCreateRequest {
+	return client.ZnsTplCreateRequest{
+		TemplateName: "Xác nhận đơn hàng",
+		TemplateType: client.ZNS_TPL_TYPE_CUSTOM,
+		Tag:          client.ZNS_TPL_TAG_TRANSACTION,
+		Layout: client.ZnsTplLayout{
+			Header: client.ZnsTplLayoutSection{Components: []client.ZnsTplComponent{
+				{Logo: &client.ZnsTplLogo{
+					Light: client.ZnsTplMedia{Type: "IMAGE", MediaID: "light"},
+					Dark:  client.ZnsTplMedia{Type: "IMAGE", MediaID: "dark"},
+				}},
+			}},
+			Body: client.ZnsTplLayoutSection{Components: []client.ZnsTplComponent{
+				{Title: &client.ZnsTplText{Value: "Đơn hàng <order_code> đã được xác nhận"}},
+				{Paragraph: &client.ZnsTplText{Value: "Chào <customer_name>, cảm ơn bạn đã mua hàng."}},
+				{Table: &client.ZnsTplTable{Rows: []client.ZnsTplTableRow{
+					{Title: "Tổng tiền", Value: "<cost>"},
+				}}},
+			}},
+			Footer: client.ZnsTplLayoutSection{Components: []client.ZnsTplComponent{
+				{Buttons: &client.ZnsTplButtons{Items: []client.ZnsTplButton{
+					{Type: client.ZNS_TPL_BUTTON_URL, Title: "Xem đơn hàng", Content: "https://example.com/orders"},
+				}}},
+			}},
+		},
+		Params: []client.ZnsTplCreateParam{
+			{Type: client.ZNS_TPL_CREATE_PARAM_CODE, Name: "order_code", SampleValue: "A12345"},
+			{Type: client.ZNS_TPL_CREATE_PARAM_CUSTOMER_NAME, Name: "customer_name", SampleValue: "Nguyễn Văn A"},
+			{Type: client.ZNS_TPL_CREATE_PARAM_CURRENCY, Name: "cost", SampleValue: "250.000"},
+		},
+		TrackingID: "create-1",
+	}
+}
+
+func TestZnsTplCreateRequestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(r *client.ZnsTplCreateRequest)
+		want   string // Part of the error message, empty if valid
+	}{
+		{"valid", func(r *client.ZnsTplCreateRequest) {}, ""},
+		{"short name", func(r *client.ZnsTplCreateRequest) { r.TemplateName = "Đơn hàng" }, "template name must have 10 to 60 characters, got 8"},
+		{"unknown type", func(r *client.ZnsTplCreateRequest) { r.TemplateType = 9 }, "template type 9 is unknown"},
+		{"unknown tag", func(r *client.ZnsTplCreateRequest) { r.Tag = "4" }, `template tag "4" is unknown`},
+		{"no tracking ID", func(r *client.ZnsTplCreateRequest) { r.TrackingID = "" }, "tracking ID is required"},
+		{"no title", func(r *client.ZnsTplCreateRequest) {
+			r.Layout.Body.Components = r.Layout.Body.Components[1:]
+			r.Params = r.Params[1:]
+		}, "layout must have exactly one title, got 0"},
+		{"long title", func(r *client.ZnsTplCreateRequest) {
+			r.Layout.Body.Components[0].Title.Value = strings.Repeat("x", 66)
+			r.Params = r.Params[1:]
+		}, "title must have up to 65 characters, got 66"},
+		{"two contents", func(r *client.ZnsTplCreateRequest) {
+			r.Layout.Body.Components[1].Title = &client.ZnsTplText{Value: "Title"}
+		}, "body component 2 must have exactly one content, got 2"},
+		{"buttons in body", func(r *client.ZnsTplCreateRequest) {
+			r.Layout.Body.Components = append(r.Layout.Body.Components, r.Layout.Footer.Components...)
+		}, "buttons must be in the footer"},
+		{"too many buttons", func(r *client.ZnsTplCreateRequest) {
+			buttons := r.Layout.Footer.Components[0].Buttons
+			buttons.Items = append(buttons.Items, buttons.Items[0], buttons.Items[0])
+		}, "layout must have at most 2 buttons, got 3"},
+		{"undefined param", func(r *client.ZnsTplCreateRequest) { r.Params = r.Params[:2] }, "parameter cost is used in the layout but not defined"},
+		{"unused param", func(r *client.ZnsTplCreateRequest) {
+			r.Params = append(r.Params, client.ZnsTplCreateParam{Type: client.ZNS_TPL_CREATE_PARAM_OTP, Name: "otp", SampleValue: "123456"})
+		}, "parameters otp are defined but not used in the layout"},
+		{"duplicate param", func(r *client.ZnsTplCreateRequest) { r.Params = append(r.Params, r.Params[0]) }, "parameter order_code is defined twice"},
+		{"unknown param type", func(r *client.ZnsTplCreateRequest) { r.Params[0].Type = "99" }, `parameter order_code has unknown type "99"`},
+		{"long sample value", func(r *client.ZnsTplCreateRequest) { r.Params[2].SampleValue = "1.000.000.000.000" }, "sample value of parameter cost must have up to 12 characters, got 17"},
+	}
+	for _, tt := range tests {
+		request := newCreateRequest()
+		tt.change(&request)
+
+		err := request.Validate()
+		if tt.want == "" {
+			assert.NoError(t, err, tt.name)
+			continue
+		}
+		assert.ErrorIs(t, err, client.ErrInvalidParameter, tt.name)
+		assert.ErrorContains(t, err, tt.want, tt.name)
+	}
+}
+
+func TestCreateZnsTemplate(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 100, Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+	zc := srv.Client()
+	ctx := context.Background()
+
+	response, err := zc.CreateZnsTemplate(ctx, newCreateRequest())
+	require.NoError(t, err)
+	require.NoError(t, response.Err())
+	assert.Equal(t, "101", response.Data.TemplateID)
+	assert.Equal(t, client.ZNS_TPL_STATUS_PENDING_REVIEW_NAME, response.Data.TemplateStatus)
+
+	detail, err := zc.GetZnsTemplateDetail(ctx, response.Data.TemplateID)
+	require.NoError(t, err)
+	assert.Equal(t, "Xác nhận đơn hàng", detail.Data.TemplateName)
+	require.NotNil(t, detail.Data.Layout)
+	assert.Equal(t, []string{"order_code", "customer_name", "cost"}, detail.Data.Layout.Params())
+	assert.Equal(t, client.ZnsTplDetailParam{Name: "cost", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_CURRENCY, MaxLength: 12}, detail.Data.ListParams[2])
+
+	// Invalid requests are not submitted
+	request := newCreateRequest()
+	request.TrackingID = ""
+	response, err = zc.CreateZnsTemplate(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, client.INVALID_PARAMETER, response.Error)
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_TEMPLATE_CREATE), 1)
+
+	srv.InjectError(client.ENDPOINT_TEMPLATE_CREATE, client.OA_NO_PERMISSION_CREATE_TEMPLATE)
+	response, err = zc.CreateZnsTemplate(ctx, newCreateRequest())
+	require.NoError(t, err)
+	assert.True(t, errors.Is(response.Err(), client.ErrNoPermissionCreateTemplate))
+	assert.False(t, errors.Is(response.Err(), client.ErrNoPermissionUseResource))
+}
@@ -0,0 +1,77 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func TestGetZnsTemplateList(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 1, TemplateName: "otp", Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 2, TemplateName: "promo", Status: client.ZNS_TPL_STATUS_REJECTED_NAME})
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 3, TemplateName: "invoice", Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+
+	zc := srv.Client()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		request client.ZnsTplListRequest
+		ids     []int
+		total   int
+	}{
+		{"all", client.ZnsTplListRequest{Limit: 10}, []int{1, 2, 3}, 3},
+		{"paged", client.ZnsTplListRequest{Offset: 1, Limit: 1}, []int{2}, 3},
+		{"negative limit is clamped", client.ZnsTplListRequest{Limit: -1}, []int{1, 2, 3}, 3},
+		{"by status", client.ZnsTplListRequest{Limit: 10, Status: client.ZNS_TPL_STATUS_ENABLED_CODE}, []int{1, 3}, 2},
+	}
+
+	for _, tt := range tests {
+		response, err := zc.GetZnsTemplateList(ctx, tt.request)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, client.SUCCESS, response.Error, tt.name)
+		assert.Equal(t, tt.total, response.Metadata.Total, tt.name)
+
+		var ids []int
+		for _, record := range response.Data {
+			ids = append(ids, record.TemplateID)
+		}
+		assert.Equal(t, tt.ids, ids, tt.name)
+	}
+}
+
+func TestGetZnsTemplateDetail(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+
+	tpl := client.ZnsTplDetailData{
+		TemplateID:   42,
+		TemplateName: "otp",
+		Status:       client.ZNS_TPL_STATUS_ENABLED_NAME,
+		ListParams:   []client.ZnsTplDetailParam{{Name: "otp", Require: true, Type: "STRING", MaxLength: 6}},
+		Price:        "300",
+	}
+	srv.AddTemplate(tpl)
+	zc := srv.Client()
+
+	response, err := zc.GetZnsTemplateDetail(context.Background(), "42")
+	require.NoError(t, err)
+	assert.Equal(t, tpl, response.Data)
+
+	response, err = zc.GetZnsTemplateDetail(context.Background(), "43")
+	require.NoError(t, err)
+	assert.Equal(t, client.TEMPLATE_ID_INVALID, response.Error)
+
+	zc.SetAccessToken(client.AccessToken{AccessToken: "expired"})
+	response, err = zc.GetZnsTemplateDetail(context.Background(), "42")
+	require.NoError(t, err)
+	assert.Equal(t, client.ACCESS_TOKEN_INVALID, response.Error)
+}
@@ -0,0 +1,82 @@
+// Command znsgen generates typed Go code for ZNS templates.
+//
+// It gets the detail of each template ID given as argument with GetZnsTemplateDetail and
+// writes the code generated by the tplgen package, e.g. in a go:generate directive:
+//
+//	//go:generate znsgen -pkg notifications -o templates_gen.go 230116 230117
+//
+// The client is configured like the examples: ZALO_APP_ID, ZALO_SECRET_KEY and
+// ZALO_CODE_VERIFIER hold the credentials and ZALO_TOKEN holds the JSON access token.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/x/atomicfile"
+	"github.com/ducminhgd/zalo-go-sdk/x/tplgen"
+)
+
+func main() {
+	pkg := flag.String("pkg", "templates", "name of the generated package")
+	output := flag.String("o", "", "file to write, standard output if empty")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: znsgen [-pkg name] [-o file] template_id...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(context.Background(), *pkg, *output, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "znsgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, pkg, output string, templateIDs []string) error {
+	zc := client.NewZaloClient(
+		os.Getenv("ZALO_APP_ID"),
+		os.Getenv("ZALO_SECRET_KEY"),
+		os.Getenv("ZALO_CODE_VERIFIER"),
+	)
+	var token client.AccessToken
+	if err := json.Unmarshal([]byte(os.Getenv("ZALO_TOKEN")), &token); err != nil {
+		return fmt.Errorf("ZALO_TOKEN: %w", err)
+	}
+	if !token.Valid() {
+		return errors.New("access token has expired")
+	}
+	zc.SetAccessToken(token)
+
+	templates := make([]client.ZnsTplDetailData, 0, len(templateIDs))
+	for _, id := range templateIDs {
+		response, err := zc.GetZnsTemplateDetail(ctx, id)
+		if err != nil {
+			return fmt.Errorf("template %s: %w", id, err)
+		}
+		if response.Error != client.SUCCESS {
+			return fmt.Errorf("template %s: error code %d: %s", id, response.Error, response.Message)
+		}
+		templates = append(templates, response.Data)
+	}
+
+	command := "znsgen -pkg " + pkg + " " + strings.Join(templateIDs, " ")
+	source, err := tplgen.Generate(tplgen.Options{Package: pkg, Command: command}, templates...)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return atomicfile.WriteFile(output, source, 0o644)
+}
@@ -0,0 +1,325 @@
+// Package outbox implements a durable outbox for ZNS messages.
+//
+// An intent to send a message is persisted in a Store, keyed by its tracking ID,
+// before any call to Zalo. A dispatcher then sends the due intents with a ZaloClient,
+// retrying transient failures, and records the outcome and message ID. An intent
+// that already has a message ID is never sent again, so a message accepted by the
+// caller survives a crash and a message accepted by Zalo is not sent twice.
+//
+// Before sending, a dispatcher claims the intent by marking it STATUS_SENDING, with a
+// conditional update of the Store, so that two dispatchers never send the same intent
+// and an intent canceled or rescheduled in the meantime is not sent. A crash between
+// the call to Zalo and recording its outcome leaves the intent claimed until
+// Options.SendTimeout, after which it is sent again, so delivery is at least once.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+var (
+	// ErrMissingTrackingID is returned when enqueuing a request without tracking ID.
+	ErrMissingTrackingID = errors.New("outbox: request has no tracking_id")
+	// ErrNotPending is returned when canceling or rescheduling an intent that is no longer pending.
+	ErrNotPending = errors.New("outbox: intent is not pending")
+)
+
+// Status is the delivery status of an intent.
+type Status string
+
+const (
+	STATUS_PENDING  Status = "PENDING"  // Waiting to be sent or retried
+	STATUS_SENDING  Status = "SENDING"  // Claimed by a dispatcher until NextAttemptAt
+	STATUS_SENT     Status = "SENT"     // Accepted by Zalo, MsgID is set
+	STATUS_FAILED   Status = "FAILED"   // Rejected by Zalo or out of attempts
+	STATUS_CANCELED Status = "CANCELED" // Canceled before being sent
+)
+
+// Intent is a persisted intent to send a ZNS message.
+type Intent struct {
+	TrackingID    string                   `json:"tracking_id"`
+	Request       client.ZnsSendMsgRequest `json:"request"`
+	Status        Status                   `json:"status"`
+	Attempts      int                      `json:"attempts"`
+	MsgID         string                   `json:"msg_id,omitempty"`
+	ErrorCode     int                      `json:"error_code,omitempty"` // Zalo error code of the last attempt
+	LastError     string                   `json:"last_error,omitempty"` // Request error of the last attempt
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+	NextAttemptAt time.Time                `json:"next_attempt_at"`
+	Version       int                      `json:"version"` // Incremented by the store on every update
+}
+
+// Options configures an Outbox. Zero values are replaced by defaults.
+type Options struct {
+	MaxAttempts int           // Attempts before an intent fails, default 5
+	RetryDelay  time.Duration // Delay before the first retry, doubled on each retry, default 30 seconds
+	MaxDelay    time.Duration // Upper bound of the retry delay, default 1 hour
+	BatchSize   int           // Intents sent per Dispatch call, default 100
+	SendTimeout time.Duration // How long a claimed intent is held before it may be sent again, default 5 minutes
+
+	// Retryable tells whether an attempt failing with a Zalo error code or a request error
+	// should be retried. The code of a *client.ZnsError wrapped by the request error, e.g. when
+	// the template detail cannot be got, is passed as the Zalo error code. By default the codes in
+	// RETRYABLE_CODES and request errors other than client.ErrE2EETemplate and *client.ZnsError
+	// are retried.
+	Retryable func(code int, err error) bool
+
+	// Defer, if set, is called when an attempt fails with a Zalo error code. If it returns true,
+	// the intent stays pending until the returned time instead of being retried or failed,
+	// e.g. to wait for the end of quiet hours.
+	Defer func(intent Intent, code int) (time.Time, bool)
+}
+
+// RETRYABLE_CODES are the Zalo error codes retried by default: they depend on the
+// state of the service or the OA rather than on the message itself.
+var RETRYABLE_CODES = map[int]bool{
+	client.UNKNOWN_ERROR:               true,
+	client.OUT_OF_QUOTA:                true,
+	client.ACCESS_TOKEN_INVALID:        true,
+	client.OA_EXCEED_DAILY_MSG_LIMIT:   true,
+	client.TEMPLATE_EXCEED_DAILY_QUOTA: true,
+	client.ZNS_OUT_OF_DAILY_QUOTA:      true,
+}
+
+// Outbox persists send intents and dispatches them with a ZaloClient.
+type Outbox struct {
+	store   Store
+	client  *client.ZaloClient
+	options Options
+	now     func() time.Time
+}
+
+// New creates an outbox storing intents in store and sending them with zc.
+func New(store Store, zc *client.ZaloClient, options Options) *Outbox {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 5
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = 30 * time.Second
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = time.Hour
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 100
+	}
+	if options.SendTimeout <= 0 {
+		options.SendTimeout = 5 * time.Minute
+	}
+	if options.Retryable == nil {
+		options.Retryable = func(code int, err error) bool {
+			var zErr *client.ZnsError
+			return RETRYABLE_CODES[code] ||
+				(err != nil && !errors.Is(err, client.ErrE2EETemplate) && !errors.As(err, &zErr))
+		}
+	}
+	return &Outbox{store: store, client: zc, options: options, now: time.Now}
+}
+
+// Enqueue persists the intent to send a request, due immediately.
+//
+// The request must have a tracking ID. Enqueuing a tracking ID that already exists
+// does not create a new intent and returns the existing one, so callers can safely
+// retry Enqueue.
+func (o *Outbox) Enqueue(ctx context.Context, request client.ZnsSendMsgRequest) (Intent, error) {
+	return o.EnqueueAt(ctx, request, o.now())
+}
+
+// EnqueueAt persists the intent to send a request, due at a time. See Enqueue.
+func (o *Outbox) EnqueueAt(ctx context.Context, request client.ZnsSendMsgRequest, at time.Time) (Intent, error) {
+	if request.TrackingID == "" {
+		return Intent{}, ErrMissingTrackingID
+	}
+
+	now := o.now()
+	intent := Intent{
+		TrackingID:    request.TrackingID,
+		Request:       request,
+		Status:        STATUS_PENDING,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		NextAttemptAt: at,
+	}
+	err := o.store.Create(ctx, intent)
+	if errors.Is(err, ErrDuplicate) {
+		return o.store.Get(ctx, request.TrackingID)
+	}
+	return intent, err
+}
+
+// Get returns the intent of a tracking ID, or ErrNotFound.
+func (o *Outbox) Get(ctx context.Context, trackingID string) (Intent, error) {
+	return o.store.Get(ctx, trackingID)
+}
+
+// Cancel cancels a pending intent so that it is never sent, or returns ErrNotPending.
+// An intent being sent is not pending: the send is not interrupted and Cancel fails.
+func (o *Outbox) Cancel(ctx context.Context, trackingID string) (Intent, error) {
+	return o.updatePending(ctx, trackingID, func(intent *Intent) {
+		intent.Status = STATUS_CANCELED
+	})
+}
+
+// Reschedule changes the due time of a pending intent, or returns ErrNotPending.
+func (o *Outbox) Reschedule(ctx context.Context, trackingID string, at time.Time) (Intent, error) {
+	return o.updatePending(ctx, trackingID, func(intent *Intent) {
+		intent.NextAttemptAt = at
+	})
+}
+
+// updatePending applies a change to a pending intent and stores it,
+// reloading the intent if it was updated concurrently, e.g. claimed by a dispatcher.
+func (o *Outbox) updatePending(ctx context.Context, trackingID string, change func(intent *Intent)) (Intent, error) {
+	for {
+		intent, err := o.store.Get(ctx, trackingID)
+		if err != nil {
+			return intent, err
+		}
+		if intent.Status != STATUS_PENDING {
+			return intent, ErrNotPending
+		}
+
+		change(&intent)
+		intent.UpdatedAt = o.now()
+		err = o.store.Update(ctx, intent)
+		if errors.Is(err, ErrConflict) {
+			continue
+		}
+		if err == nil {
+			intent.Version++
+		}
+		return intent, err
+	}
+}
+
+// Dispatch sends one batch of due intents and returns how many were attempted.
+func (o *Outbox) Dispatch(ctx context.Context) (int, error) {
+	due, err := o.store.Due(ctx, o.now(), o.options.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for _, intent := range due {
+		if err := ctx.Err(); err != nil {
+			return attempted, err
+		}
+		sent, err := o.dispatch(ctx, intent.TrackingID)
+		if err != nil {
+			return attempted, err
+		}
+		if sent {
+			attempted++
+		}
+	}
+	return attempted, nil
+}
+
+// Run dispatches due intents every interval until ctx is done, and returns the context error.
+// Store errors are logged and retried on the next tick.
+func (o *Outbox) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := o.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			o.client.GetLogger().ErrorContext(ctx, "Error dispatching outbox:", slog.Any("err", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch claims one intent, sends it and records the outcome. It reports whether a send was attempted.
+func (o *Outbox) dispatch(ctx context.Context, trackingID string) (bool, error) {
+	// Reload the intent: it may have been sent, canceled or rescheduled since Due was called
+	intent, err := o.store.Get(ctx, trackingID)
+	if err != nil {
+		return false, err
+	}
+	now := o.now()
+	if intent.MsgID != "" || (intent.Status != STATUS_PENDING && intent.Status != STATUS_SENDING) ||
+		intent.NextAttemptAt.After(now) {
+		return false, nil
+	}
+
+	claimed := intent
+	claimed.Status = STATUS_SENDING
+	claimed.UpdatedAt = now
+	claimed.NextAttemptAt = now.Add(o.options.SendTimeout)
+	err = o.store.Update(ctx, claimed)
+	if errors.Is(err, ErrConflict) {
+		// Claimed, canceled or rescheduled by someone else
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	claimed.Version++
+
+	response, sendErr := o.client.SendZnsMessage(ctx, intent.Request)
+	if sendErr != nil && ctx.Err() != nil {
+		// Interrupted by the caller: the attempt does not count. If the claim cannot be
+		// released with the canceled context, the intent is sent again once it expires.
+		intent.Version = claimed.Version
+		_ = o.store.Update(ctx, intent)
+		return false, ctx.Err()
+	}
+
+	intent = claimed
+	intent.Status = STATUS_PENDING
+	now = o.now()
+	intent.Attempts++
+	intent.UpdatedAt = now
+	intent.ErrorCode = response.Error
+	intent.LastError = ""
+	if sendErr != nil {
+		intent.LastError = sendErr.Error()
+	}
+	var zErr *client.ZnsError
+	if errors.As(sendErr, &zErr) {
+		intent.ErrorCode = zErr.Code
+	}
+
+	var deferUntil time.Time
+	deferred := false
+	if intent.ErrorCode != client.SUCCESS && o.options.Defer != nil {
+		deferUntil, deferred = o.options.Defer(intent, intent.ErrorCode)
+	}
+
+	switch {
+	case sendErr == nil && response.Error == client.SUCCESS:
+		intent.Status = STATUS_SENT
+		intent.MsgID = response.Data.MsgID
+	case deferred:
+		intent.NextAttemptAt = deferUntil
+	case o.options.Retryable(intent.ErrorCode, sendErr) && intent.Attempts < o.options.MaxAttempts:
+		intent.NextAttemptAt = now.Add(o.backoff(intent.Attempts))
+	default:
+		intent.Status = STATUS_FAILED
+	}
+
+	return true, o.store.Update(ctx, intent)
+}
+
+// backoff returns the delay before the next attempt after a number of attempts.
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.options.RetryDelay
+	for i := 1; i < attempts && delay < o.options.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > o.options.MaxDelay {
+		delay = o.options.MaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,246 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func newTestServer() *zalotest.Server {
+	srv := zalotest.NewServer()
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 100, Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+	return srv
+}
+
+func request(trackingID string) client.ZnsSendMsgRequest {
+	return client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "100", TrackingID: trackingID}
+}
+
+func TestOutboxDispatch(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	ob := New(store, srv.Client(), Options{})
+
+	_, err = ob.Enqueue(ctx, client.ZnsSendMsgRequest{TemplateID: "100"})
+	assert.ErrorIs(t, err, ErrMissingTrackingID)
+
+	_, err = ob.Enqueue(ctx, request("order-1"))
+	require.NoError(t, err)
+	_, err = ob.Enqueue(ctx, request("order-1"))
+	require.NoError(t, err, "enqueuing twice is not an error")
+	_, err = ob.Enqueue(ctx, request("order-2"))
+	require.NoError(t, err)
+
+	attempted, err := ob.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempted)
+	require.Len(t, srv.Messages(), 2)
+
+	intent, err := ob.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, STATUS_SENT, intent.Status)
+	assert.Equal(t, srv.Messages()[0].MsgID, intent.MsgID)
+	assert.Equal(t, 1, intent.Attempts)
+
+	// A restarted process never sends an intent with a message ID again
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+	ob = New(store, srv.Client(), Options{})
+	intent, err = ob.Enqueue(ctx, request("order-1"))
+	require.NoError(t, err)
+	assert.Equal(t, STATUS_SENT, intent.Status)
+
+	attempted, err = ob.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, attempted)
+	assert.Len(t, srv.Messages(), 2)
+}
+
+func TestOutboxRetries(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	ob := New(NewMemoryStore(), srv.Client(), Options{MaxAttempts: 3, RetryDelay: time.Minute})
+	ob.now = func() time.Time { return now }
+
+	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.UNKNOWN_ERROR)
+	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.UNKNOWN_ERROR)
+	_, err := ob.Enqueue(ctx, request("order-1"))
+	require.NoError(t, err)
+
+	_, err = ob.Dispatch(ctx)
+	require.NoError(t, err)
+	intent, _ := ob.Get(ctx, "order-1")
+	assert.Equal(t, STATUS_PENDING, intent.Status)
+	assert.Equal(t, client.UNKNOWN_ERROR, intent.ErrorCode)
+	assert.Equal(t, now.Add(time.Minute), intent.NextAttemptAt)
+
+	attempted, err := ob.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, attempted, "the retry is not due yet")
+
+	now = now.Add(time.Minute)
+	_, err = ob.Dispatch(ctx)
+	require.NoError(t, err)
+	intent, _ = ob.Get(ctx, "order-1")
+	assert.Equal(t, now.Add(2*time.Minute), intent.NextAttemptAt, "the delay doubles")
+
+	now = now.Add(2 * time.Minute)
+	_, err = ob.Dispatch(ctx)
+	require.NoError(t, err)
+	intent, _ = ob.Get(ctx, "order-1")
+	assert.Equal(t, STATUS_SENT, intent.Status)
+	assert.Equal(t, 3, intent.Attempts)
+	assert.Len(t, srv.Messages(), 1)
+}
+
+func TestOutboxPermanentFailure(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	ob := New(NewMemoryStore(), srv.Client(), Options{})
+	_, err := ob.Enqueue(ctx, client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "404", TrackingID: "order-1"})
+	require.NoError(t, err)
+
+	_, err = ob.Dispatch(ctx)
+	require.NoError(t, err)
+	intent, _ := ob.Get(ctx, "order-1")
+	assert.Equal(t, STATUS_FAILED, intent.Status)
+	assert.Equal(t, client.TEMPLATE_ID_INVALID, intent.ErrorCode)
+}
+
+// roundTripFunc calls a function before each request of a test client.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestOutboxCancelDuringSend(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	zc := srv.Client()
+	ob := New(NewMemoryStore(), zc, Options{})
+	_, err := ob.Enqueue(ctx, request("order-1"))
+	require.NoError(t, err)
+
+	// The intent is canceled and rescheduled while Zalo is handling the send
+	transport := zc.GetHTTPClient().Transport
+	zc.UseHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		_, err := ob.Cancel(ctx, "order-1")
+		assert.ErrorIs(t, err, ErrNotPending)
+		_, err = ob.Reschedule(ctx, "order-1", time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrNotPending)
+		return transport.RoundTrip(req)
+	})})
+
+	attempted, err := ob.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	intent, err := ob.Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, STATUS_SENT, intent.Status, "the outcome of the send is not lost")
+	assert.Equal(t, srv.Messages()[0].MsgID, intent.MsgID)
+}
+
+func TestOutboxConcurrentDispatch(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for i := 0; i < 50; i++ {
+		_, err := New(store, srv.Client(), Options{}).Enqueue(ctx, request(fmt.Sprintf("order-%d", i)))
+		require.NoError(t, err)
+	}
+
+	// Two processes share the store: every intent is sent exactly once
+	var wg sync.WaitGroup
+	var attempted [2]int
+	for i := range attempted {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ob := New(store, srv.Client(), Options{})
+			var err error
+			attempted[i], err = ob.Dispatch(ctx)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 50, attempted[0]+attempted[1])
+	assert.Len(t, srv.Messages(), 50)
+}
+
+func TestOutboxExpiredClaim(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	store := NewMemoryStore()
+	ob := New(store, srv.Client(), Options{SendTimeout: time.Minute})
+	ob.now = func() time.Time { return now }
+	intent, err := ob.Enqueue(ctx, request("order-1"))
+	require.NoError(t, err)
+
+	// A dispatcher crashed after claiming the intent
+	intent.Status = STATUS_SENDING
+	intent.NextAttemptAt = now.Add(time.Minute)
+	require.NoError(t, store.Update(ctx, intent))
+	assert.ErrorIs(t, store.Update(ctx, intent), ErrConflict, "the intent was updated since it was read")
+
+	attempted, err := ob.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, attempted, "the claim is held until it expires")
+
+	now = now.Add(time.Minute)
+	attempted, err = ob.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	intent, _ = ob.Get(ctx, "order-1")
+	assert.Equal(t, STATUS_SENT, intent.Status)
+}
+
+func TestFileStorePrune(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, store.Create(ctx, Intent{TrackingID: "sent", Status: STATUS_SENT, UpdatedAt: old}))
+	require.NoError(t, store.Create(ctx, Intent{TrackingID: "pending", Status: STATUS_PENDING, UpdatedAt: old}))
+	assert.ErrorIs(t, store.Create(ctx, Intent{TrackingID: "sent"}), ErrDuplicate)
+
+	removed, err := store.Prune(time.Now().Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	store, err = OpenFileStore(path)
+	require.NoError(t, err)
+	_, err = store.Get(ctx, "sent")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.Get(ctx, "pending")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,239 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/x/atomicfile"
+)
+
+var (
+	ErrNotFound  = errors.New("outbox: intent not found")
+	ErrDuplicate = errors.New("outbox: intent already exists")
+	ErrConflict  = errors.New("outbox: intent was updated concurrently")
+)
+
+// Store persists send intents keyed by tracking ID.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Create inserts a new intent, or returns ErrDuplicate if its tracking ID exists.
+	Create(ctx context.Context, intent Intent) error
+	// Get returns the intent of a tracking ID, or ErrNotFound.
+	Get(ctx context.Context, trackingID string) (Intent, error)
+	// Update replaces an existing intent if its stored Version is intent.Version, and stores it
+	// with the next Version. It returns ErrNotFound, or ErrConflict if the intent was updated
+	// since it was read.
+	Update(ctx context.Context, intent Intent) error
+	// Due returns up to limit pending or sending intents whose next attempt is at or before now,
+	// oldest first.
+	Due(ctx context.Context, now time.Time, limit int) ([]Intent, error)
+}
+
+// MemoryStore is a Store keeping intents in memory, e.g. for tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	intents map[string]Intent
+}
+
+// NewMemoryStore creates an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{intents: make(map[string]Intent)}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, intent Intent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.intents[intent.TrackingID]; ok {
+		return ErrDuplicate
+	}
+	s.intents[intent.TrackingID] = intent
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, trackingID string) (Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	intent, ok := s.intents[trackingID]
+	if !ok {
+		return Intent{}, ErrNotFound
+	}
+	return intent, nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, intent Intent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.intents[intent.TrackingID]
+	if !ok {
+		return ErrNotFound
+	}
+	if previous.Version != intent.Version {
+		return ErrConflict
+	}
+	intent.Version++
+	s.intents[intent.TrackingID] = intent
+	return nil
+}
+
+func (s *MemoryStore) Due(ctx context.Context, now time.Time, limit int) ([]Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return dueIntents(s.intents, now, limit), nil
+}
+
+// FileStore is a Store keeping intents as JSON in a single file, for embedded use.
+//
+// Intents are held in memory and the whole file is atomically rewritten on every change,
+// so it suits outboxes of up to a few thousand pending intents. Sent and failed intents
+// are kept until removed with Prune.
+type FileStore struct {
+	path string
+
+	mu      sync.Mutex
+	intents map[string]Intent
+}
+
+// OpenFileStore opens the file store at path, creating it on the first write if it does not exist.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, intents: make(map[string]Intent)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var intents []Intent
+	if err := json.Unmarshal(data, &intents); err != nil {
+		return nil, err
+	}
+	for _, intent := range intents {
+		s.intents[intent.TrackingID] = intent
+	}
+	return s, nil
+}
+
+func (s *FileStore) Create(ctx context.Context, intent Intent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.intents[intent.TrackingID]; ok {
+		return ErrDuplicate
+	}
+	s.intents[intent.TrackingID] = intent
+	if err := s.save(); err != nil {
+		delete(s.intents, intent.TrackingID)
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) Get(ctx context.Context, trackingID string) (Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	intent, ok := s.intents[trackingID]
+	if !ok {
+		return Intent{}, ErrNotFound
+	}
+	return intent, nil
+}
+
+func (s *FileStore) Update(ctx context.Context, intent Intent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.intents[intent.TrackingID]
+	if !ok {
+		return ErrNotFound
+	}
+	if previous.Version != intent.Version {
+		return ErrConflict
+	}
+	intent.Version++
+	s.intents[intent.TrackingID] = intent
+	if err := s.save(); err != nil {
+		s.intents[intent.TrackingID] = previous
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) Due(ctx context.Context, now time.Time, limit int) ([]Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return dueIntents(s.intents, now, limit), nil
+}
+
+// Prune removes sent, failed and canceled intents last updated before a time, and returns how many were removed.
+func (s *FileStore) Prune(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := make(map[string]Intent)
+	for id, intent := range s.intents {
+		done := intent.Status != STATUS_PENDING && intent.Status != STATUS_SENDING
+		if done && intent.UpdatedAt.Before(before) {
+			removed[id] = intent
+			delete(s.intents, id)
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+	if err := s.save(); err != nil {
+		for id, intent := range removed {
+			s.intents[id] = intent
+		}
+		return 0, err
+	}
+	return len(removed), nil
+}
+
+// save writes every intent to the file. The caller holds mu.
+func (s *FileStore) save() error {
+	intents := make([]Intent, 0, len(s.intents))
+	for _, intent := range s.intents {
+		intents = append(intents, intent)
+	}
+	sort.Slice(intents, func(i, j int) bool {
+		return intents[i].TrackingID < intents[j].TrackingID
+	})
+
+	data, err := json.Marshal(intents)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path, data, 0o600)
+}
+
+// dueIntents returns up to limit pending intents and expired claims due at now, oldest first.
+func dueIntents(intents map[string]Intent, now time.Time, limit int) []Intent {
+	var due []Intent
+	for _, intent := range intents {
+		waiting := intent.Status == STATUS_PENDING || intent.Status == STATUS_SENDING
+		if waiting && !intent.NextAttemptAt.After(now) {
+			due = append(due, intent)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].CreatedAt.Equal(due[j].CreatedAt) {
+			return due[i].CreatedAt.Before(due[j].CreatedAt)
+		}
+		return due[i].TrackingID < due[j].TrackingID
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due
+}
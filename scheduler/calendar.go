@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+// Calendar tells when messages may be sent.
+type Calendar interface {
+	// Next returns the earliest time at or after t when sending is allowed.
+	Next(t time.Time) time.Time
+}
+
+// CalendarFunc adapts a function to a Calendar.
+type CalendarFunc func(t time.Time) time.Time
+
+func (f CalendarFunc) Next(t time.Time) time.Time {
+	return f(t)
+}
+
+// QuietHours is a Calendar forbidding sends every day between two times of day.
+// Start may be after End for quiet hours spanning midnight.
+type QuietHours struct {
+	Start    time.Duration // Start of the quiet hours, as time since midnight
+	End      time.Duration // End of the quiet hours, as time since midnight
+	Location *time.Location
+}
+
+// NIGHT_QUIET_HOURS are the hours when Zalo rejects templates with THIS_TEMPLATE_CANNOT_SENT_AT_NIGHT:
+// 22:00 to 06:00 Vietnam time.
+var NIGHT_QUIET_HOURS = QuietHours{Start: 22 * time.Hour, End: 6 * time.Hour, Location: client.VietnamLocation}
+
+func (q QuietHours) Next(t time.Time) time.Time {
+	location := q.Location
+	if location == nil {
+		location = client.VietnamLocation
+	}
+	local := t.In(location)
+	year, month, day := local.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, location)
+	offset := local.Sub(midnight)
+
+	switch {
+	case q.Start == q.End:
+		return t
+	case q.Start < q.End:
+		if offset >= q.Start && offset < q.End {
+			return midnight.Add(q.End)
+		}
+	case offset >= q.Start:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, location).Add(q.End)
+	case offset < q.End:
+		return midnight.Add(q.End)
+	}
+	return t
+}
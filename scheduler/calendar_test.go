@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+func vnTime(day, hour, minute int) time.Time {
+	return time.Date(2024, time.March, day, hour, minute, 0, 0, client.VietnamLocation)
+}
+
+func TestQuietHoursNext(t *testing.T) {
+	office := QuietHours{Start: 12 * time.Hour, End: 13*time.Hour + 30*time.Minute, Location: client.VietnamLocation}
+
+	tests := []struct {
+		name     string
+		calendar QuietHours
+		t        time.Time
+		want     time.Time
+	}{
+		{"night, evening", NIGHT_QUIET_HOURS, vnTime(10, 21, 59), vnTime(10, 21, 59)},
+		{"night, start", NIGHT_QUIET_HOURS, vnTime(10, 22, 0), vnTime(11, 6, 0)},
+		{"night, after midnight", NIGHT_QUIET_HOURS, vnTime(11, 3, 15), vnTime(11, 6, 0)},
+		{"night, end", NIGHT_QUIET_HOURS, vnTime(11, 6, 0), vnTime(11, 6, 0)},
+		{"night, UTC input", NIGHT_QUIET_HOURS, time.Date(2024, time.March, 10, 16, 0, 0, 0, time.UTC), vnTime(11, 6, 0)},
+		{"lunch break, before", office, vnTime(10, 11, 0), vnTime(10, 11, 0)},
+		{"lunch break, during", office, vnTime(10, 12, 45), vnTime(10, 13, 30)},
+		{"empty", QuietHours{}, vnTime(10, 23, 0), vnTime(10, 23, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(tt.calendar.Next(tt.t)), "got %v", tt.calendar.Next(tt.t))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/outbox"
+)
+
+// Handle identifies a scheduled send. It is the tracking ID of the request.
+type Handle string
+
+// Schedule persists a request to be sent at a time, or at the next allowed time of its template
+// if at falls in its quiet hours, and returns the handle to cancel or reschedule it.
+//
+// A tracking ID is generated if the request has none. Scheduling a tracking ID again does not
+// create a new send. Sends are dispatched at least once by Run; with an idempotency store on
+// the client, a repeated dispatch returns the original response instead of sending again.
+func (s *Scheduler) Schedule(ctx context.Context, request client.ZnsSendMsgRequest, at time.Time) (Handle, error) {
+	if request.TrackingID == "" {
+		request.TrackingID = client.NewTrackingID()
+	}
+	intent, err := s.outbox.EnqueueAt(ctx, request, s.NextAllowed(request.TemplateID, at))
+	return Handle(intent.TrackingID), err
+}
+
+// ScheduleAfter persists a request to be sent after a delay. See Schedule.
+func (s *Scheduler) ScheduleAfter(ctx context.Context, request client.ZnsSendMsgRequest, delay time.Duration) (Handle, error) {
+	return s.Schedule(ctx, request, s.now().Add(delay))
+}
+
+// Cancel cancels a scheduled send. It returns outbox.ErrNotPending if the send is already done
+// or canceled, and outbox.ErrNotFound for an unknown handle.
+func (s *Scheduler) Cancel(ctx context.Context, handle Handle) error {
+	_, err := s.outbox.Cancel(ctx, string(handle))
+	return err
+}
+
+// Reschedule moves a scheduled send to another time, subject to the calendar of its template
+// like Schedule. It returns the same errors as Cancel.
+func (s *Scheduler) Reschedule(ctx context.Context, handle Handle, at time.Time) error {
+	intent, err := s.outbox.Get(ctx, string(handle))
+	if err != nil {
+		return err
+	}
+	_, err = s.outbox.Reschedule(ctx, string(handle), s.NextAllowed(intent.Request.TemplateID, at))
+	return err
+}
+
+// Get returns the state of a scheduled send.
+func (s *Scheduler) Get(ctx context.Context, handle Handle) (outbox.Intent, error) {
+	return s.outbox.Get(ctx, string(handle))
+}
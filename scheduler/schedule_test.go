@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/outbox"
+)
+
+func TestSchedulerSchedule(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	store, err := outbox.OpenFileStore(path)
+	require.NoError(t, err)
+	s := New(store, srv.Client(), Options{QuietTemplates: []string{"100"}})
+
+	past := time.Now().Add(-time.Minute)
+	reminder, err := s.Schedule(ctx, request("", "200"), past)
+	require.NoError(t, err)
+	assert.NotEmpty(t, reminder, "a tracking ID is generated")
+
+	again, err := s.Schedule(ctx, request(string(reminder), "200"), past.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, reminder, again)
+
+	canceled, err := s.Schedule(ctx, request("booking-2", "200"), past)
+	require.NoError(t, err)
+	require.NoError(t, s.Cancel(ctx, canceled))
+
+	later, err := s.ScheduleAfter(ctx, request("booking-3", "200"), time.Hour)
+	require.NoError(t, err)
+
+	night, err := s.Schedule(ctx, request("booking-4", "100"), vnTime(10, 23, 0))
+	require.NoError(t, err)
+	require.NoError(t, s.Reschedule(ctx, night, vnTime(11, 1, 0)))
+	intent, err := s.Get(ctx, night)
+	require.NoError(t, err)
+	assert.True(t, vnTime(11, 6, 0).Equal(intent.NextAttemptAt), "rescheduling follows quiet hours")
+
+	// Scheduled sends survive a restart
+	store, err = outbox.OpenFileStore(path)
+	require.NoError(t, err)
+	s = New(store, srv.Client(), Options{})
+
+	attempted, err := s.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempted)
+	require.Len(t, srv.Messages(), 2)
+	assert.Equal(t, string(reminder), srv.Messages()[0].TrackingID)
+
+	intent, err = s.Get(ctx, later)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.STATUS_PENDING, intent.Status)
+
+	intent, err = s.Get(ctx, canceled)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.STATUS_CANCELED, intent.Status)
+
+	assert.ErrorIs(t, s.Cancel(ctx, reminder), outbox.ErrNotPending)
+	assert.ErrorIs(t, s.Reschedule(ctx, "unknown", time.Now()), outbox.ErrNotFound)
+}
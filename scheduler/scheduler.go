@@ -0,0 +1,131 @@
+// Package scheduler defers ZNS sends to the times when they are allowed.
+//
+// Some templates cannot be sent at night: Zalo rejects them with THIS_TEMPLATE_CANNOT_SENT_AT_NIGHT
+// between 22:00 and 06:00 Vietnam time. A Scheduler knows which templates are subject to quiet
+// hours, either configured or learned from such responses, and enqueues their sends in a durable
+// outbox due at the next allowed time. Templates may also follow their own business-hours calendar.
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/outbox"
+)
+
+// Options configures a Scheduler.
+type Options struct {
+	Outbox outbox.Options // Options of the underlying outbox; Defer is set by the scheduler
+
+	// QuietHours is the calendar of templates subject to quiet hours, default NIGHT_QUIET_HOURS.
+	QuietHours Calendar
+	// QuietTemplates are the IDs of templates known to be subject to quiet hours.
+	QuietTemplates []string
+	// Calendars are the calendars of templates with their own sending hours, by template ID.
+	// They replace QuietHours for these templates.
+	Calendars map[string]Calendar
+	// OnQuietTemplate, if set, is called when a template is learned to be subject to quiet hours,
+	// e.g. to persist it and pass it in QuietTemplates on the next start.
+	OnQuietTemplate func(templateID string)
+}
+
+// Scheduler enqueues ZNS sends in an outbox, due at the next time their template may be sent.
+type Scheduler struct {
+	outbox  *outbox.Outbox
+	options Options
+	now     func() time.Time
+
+	mu    sync.Mutex
+	quiet map[string]bool
+}
+
+// New creates a scheduler storing deferred sends in store and sending them with zc.
+func New(store outbox.Store, zc *client.ZaloClient, options Options) *Scheduler {
+	if options.QuietHours == nil {
+		options.QuietHours = NIGHT_QUIET_HOURS
+	}
+
+	s := &Scheduler{options: options, now: time.Now, quiet: make(map[string]bool)}
+	for _, templateID := range options.QuietTemplates {
+		s.quiet[templateID] = true
+	}
+	options.Outbox.Defer = s.deferNight
+	s.outbox = outbox.New(store, zc, options.Outbox)
+	return s
+}
+
+// Outbox returns the outbox holding the deferred sends.
+func (s *Scheduler) Outbox() *outbox.Outbox {
+	return s.outbox
+}
+
+// Send enqueues a request due at the next time its template may be sent. See outbox.Outbox.Enqueue.
+func (s *Scheduler) Send(ctx context.Context, request client.ZnsSendMsgRequest) (outbox.Intent, error) {
+	return s.outbox.EnqueueAt(ctx, request, s.NextAllowed(request.TemplateID, s.now()))
+}
+
+// NextAllowed returns the earliest time at or after t when a template may be sent.
+func (s *Scheduler) NextAllowed(templateID string, t time.Time) time.Time {
+	if calendar, ok := s.options.Calendars[templateID]; ok {
+		return calendar.Next(t)
+	}
+
+	s.mu.Lock()
+	quiet := s.quiet[templateID]
+	s.mu.Unlock()
+	if quiet {
+		return s.options.QuietHours.Next(t)
+	}
+	return t
+}
+
+// QuietTemplates returns the IDs of templates subject to quiet hours, configured or learned.
+func (s *Scheduler) QuietTemplates() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.quiet))
+	for id := range s.quiet {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Dispatch sends one batch of due sends. See outbox.Outbox.Dispatch.
+func (s *Scheduler) Dispatch(ctx context.Context) (int, error) {
+	return s.outbox.Dispatch(ctx)
+}
+
+// Run dispatches due sends every interval until ctx is done. See outbox.Outbox.Run.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) error {
+	return s.outbox.Run(ctx, interval)
+}
+
+// deferNight learns that the template of an intent rejected at night is subject to quiet hours,
+// and defers the intent to the end of the quiet hours.
+func (s *Scheduler) deferNight(intent outbox.Intent, code int) (time.Time, bool) {
+	if code != client.THIS_TEMPLATE_CANNOT_SENT_AT_NIGHT {
+		return time.Time{}, false
+	}
+
+	templateID := intent.Request.TemplateID
+	s.mu.Lock()
+	learned := !s.quiet[templateID]
+	s.quiet[templateID] = true
+	s.mu.Unlock()
+	if learned && s.options.OnQuietTemplate != nil {
+		s.options.OnQuietTemplate(templateID)
+	}
+
+	now := s.now()
+	at := s.NextAllowed(templateID, now)
+	if !at.After(now) {
+		// The calendar of the template disagrees with Zalo
+		at = NIGHT_QUIET_HOURS.Next(now)
+	}
+	return at, at.After(now)
+}
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/outbox"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func newTestServer() *zalotest.Server {
+	srv := zalotest.NewServer()
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 100, Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 200, Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+	return srv
+}
+
+func request(trackingID, templateID string) client.ZnsSendMsgRequest {
+	return client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: templateID, TrackingID: trackingID}
+}
+
+func TestSchedulerSend(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	weekend := CalendarFunc(func(t time.Time) time.Time {
+		return t.AddDate(0, 0, 1)
+	})
+	s := New(outbox.NewMemoryStore(), srv.Client(), Options{
+		QuietTemplates: []string{"100"},
+		Calendars:      map[string]Calendar{"300": weekend},
+	})
+	now := vnTime(10, 23, 0)
+	s.now = func() time.Time { return now }
+
+	tests := []struct {
+		templateID string
+		want       time.Time
+	}{
+		{"100", vnTime(11, 6, 0)},
+		{"200", now},
+		{"300", vnTime(11, 23, 0)},
+	}
+	for _, tt := range tests {
+		intent, err := s.Send(ctx, request("order-"+tt.templateID, tt.templateID))
+		require.NoError(t, err)
+		assert.True(t, tt.want.Equal(intent.NextAttemptAt), "template %s: got %v", tt.templateID, intent.NextAttemptAt)
+	}
+}
+
+func TestSchedulerLearnsQuietTemplates(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	var learned []string
+	s := New(outbox.NewMemoryStore(), srv.Client(), Options{
+		OnQuietTemplate: func(templateID string) { learned = append(learned, templateID) },
+	})
+	now := vnTime(10, 23, 0)
+	s.now = func() time.Time { return now }
+
+	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.THIS_TEMPLATE_CANNOT_SENT_AT_NIGHT)
+	_, err := s.Send(ctx, request("order-1", "100"))
+	require.NoError(t, err)
+	attempted, err := s.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+
+	intent, err := s.Outbox().Get(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, outbox.STATUS_PENDING, intent.Status, "a send rejected at night is deferred, not failed")
+	assert.True(t, vnTime(11, 6, 0).Equal(intent.NextAttemptAt))
+	assert.Equal(t, []string{"100"}, learned)
+	assert.Equal(t, []string{"100"}, s.QuietTemplates())
+
+	intent, err = s.Send(ctx, request("order-2", "100"))
+	require.NoError(t, err)
+	assert.True(t, vnTime(11, 6, 0).Equal(intent.NextAttemptAt))
+
+	// The deferred sends are due once the quiet hours are over
+	attempted, err = s.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempted)
+	assert.Len(t, srv.Messages(), 2)
+}
@@ -0,0 +1,162 @@
+// Package phone parses and normalizes Vietnamese mobile phone numbers.
+//
+// ZNS messages can only be sent to Vietnamese mobile numbers in the 84XXXXXXXXX form.
+// Parse accepts the common ways these numbers are written, e.g. "0912 345 678",
+// "+84 912.345.678" or "(84) 912-345-678", and converts the 11-digit numbers of the
+// prefixes retired in 2018, e.g. 0168, to their current 10-digit form.
+// Landlines and foreign numbers are rejected.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalid is returned for an input that is not a phone number.
+	ErrInvalid = errors.New("phone: invalid phone number")
+	// ErrNotVietnamese is returned for a phone number with a foreign country code.
+	ErrNotVietnamese = errors.New("phone: not a Vietnamese phone number")
+	// ErrLandline is returned for a Vietnamese landline phone number.
+	ErrLandline = errors.New("phone: landline phone number")
+)
+
+// COUNTRY_CODE is the country code of Vietnam.
+const COUNTRY_CODE = "84"
+
+// Carrier is a Vietnamese mobile network operator.
+type Carrier string
+
+const (
+	CARRIER_UNKNOWN      Carrier = ""
+	CARRIER_VIETTEL      Carrier = "Viettel"
+	CARRIER_VINAPHONE    Carrier = "VinaPhone"
+	CARRIER_MOBIFONE     Carrier = "MobiFone"
+	CARRIER_VIETNAMOBILE Carrier = "Vietnamobile"
+	CARRIER_GMOBILE      Carrier = "Gmobile"
+	CARRIER_ITELECOM     Carrier = "Itelecom"
+	CARRIER_REDDI        Carrier = "Reddi"
+)
+
+// carriers maps the 2-digit mobile prefixes, without the leading 0, to their carrier.
+var carriers = map[string]Carrier{
+	"32": CARRIER_VIETTEL, "33": CARRIER_VIETTEL, "34": CARRIER_VIETTEL, "35": CARRIER_VIETTEL,
+	"36": CARRIER_VIETTEL, "37": CARRIER_VIETTEL, "38": CARRIER_VIETTEL, "39": CARRIER_VIETTEL,
+	"86": CARRIER_VIETTEL, "96": CARRIER_VIETTEL, "97": CARRIER_VIETTEL, "98": CARRIER_VIETTEL,
+
+	"81": CARRIER_VINAPHONE, "82": CARRIER_VINAPHONE, "83": CARRIER_VINAPHONE, "84": CARRIER_VINAPHONE,
+	"85": CARRIER_VINAPHONE, "88": CARRIER_VINAPHONE, "91": CARRIER_VINAPHONE, "94": CARRIER_VINAPHONE,
+
+	"70": CARRIER_MOBIFONE, "76": CARRIER_MOBIFONE, "77": CARRIER_MOBIFONE, "78": CARRIER_MOBIFONE,
+	"79": CARRIER_MOBIFONE, "89": CARRIER_MOBIFONE, "90": CARRIER_MOBIFONE, "93": CARRIER_MOBIFONE,
+
+	"52": CARRIER_VIETNAMOBILE, "56": CARRIER_VIETNAMOBILE, "58": CARRIER_VIETNAMOBILE, "92": CARRIER_VIETNAMOBILE,
+
+	"59": CARRIER_GMOBILE, "99": CARRIER_GMOBILE,
+
+	"87": CARRIER_ITELECOM,
+
+	"55": CARRIER_REDDI,
+}
+
+// legacyPrefixes maps the 3-digit prefixes of 11-digit mobile numbers, without the leading 0,
+// to the prefixes that replaced them in 2018.
+var legacyPrefixes = map[string]string{
+	// Viettel
+	"162": "32", "163": "33", "164": "34", "165": "35",
+	"166": "36", "167": "37", "168": "38", "169": "39",
+	// MobiFone
+	"120": "70", "121": "79", "122": "77", "126": "76", "128": "78",
+	// VinaPhone
+	"123": "83", "124": "84", "125": "85", "127": "81", "129": "82",
+	// Vietnamobile
+	"186": "56", "188": "58",
+	// Gmobile
+	"199": "59",
+}
+
+// Number is a Vietnamese mobile phone number.
+type Number struct {
+	subscriber string // The 9 digits following the country code
+}
+
+// Parse parses a Vietnamese mobile phone number.
+// Spaces, dots, dashes and parentheses are ignored.
+func Parse(s string) (Number, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+
+	if strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+		if !strings.HasPrefix(digits, COUNTRY_CODE) {
+			return Number{}, ErrNotVietnamese
+		}
+		digits = digits[len(COUNTRY_CODE):]
+	} else if strings.HasPrefix(digits, "00") {
+		if !strings.HasPrefix(digits, "00"+COUNTRY_CODE) {
+			return Number{}, ErrNotVietnamese
+		}
+		digits = digits[2+len(COUNTRY_CODE):]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Number{}, ErrInvalid
+	}
+
+	if strings.HasPrefix(digits, COUNTRY_CODE) && len(digits) >= 11 {
+		digits = digits[len(COUNTRY_CODE):]
+	}
+	digits = strings.TrimPrefix(digits, "0")
+
+	if len(digits) == 10 && digits[0] == '1' {
+		prefix, ok := legacyPrefixes[digits[:3]]
+		if !ok {
+			return Number{}, ErrInvalid
+		}
+		digits = prefix + digits[3:]
+	}
+
+	switch {
+	case digits == "":
+		return Number{}, ErrInvalid
+	case digits[0] == '2':
+		return Number{}, ErrLandline
+	case len(digits) != 9:
+		return Number{}, ErrInvalid
+	case !strings.ContainsRune("35789", rune(digits[0])):
+		return Number{}, ErrInvalid
+	}
+	return Number{subscriber: digits}, nil
+}
+
+// Normalize parses a Vietnamese mobile phone number and returns it in the 84XXXXXXXXX form.
+func Normalize(s string) (string, error) {
+	number, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return number.String(), nil
+}
+
+// String returns the number in the 84XXXXXXXXX form required by ZNS.
+func (n Number) String() string {
+	return COUNTRY_CODE + n.subscriber
+}
+
+// National returns the number in the 0XXXXXXXXX form used inside Vietnam.
+func (n Number) National() string {
+	return "0" + n.subscriber
+}
+
+// Carrier returns the carrier of the number prefix, or CARRIER_UNKNOWN for a prefix not
+// allocated when this package was written. Numbers ported to another carrier keep their prefix.
+func (n Number) Carrier() Carrier {
+	if len(n.subscriber) < 2 {
+		return CARRIER_UNKNOWN
+	}
+	return carriers[n.subscriber[:2]]
+}
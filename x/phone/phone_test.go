@@ -0,0 +1,55 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		carrier Carrier
+		err     error
+	}{
+		{"national", "0912345678", "84912345678", CARRIER_VINAPHONE, nil},
+		{"national with spaces", "0912 345 678", "84912345678", CARRIER_VINAPHONE, nil},
+		{"international", "+84912345678", "84912345678", CARRIER_VINAPHONE, nil},
+		{"international with punctuation", "+84 (98) 765-43.21", "84987654321", CARRIER_VIETTEL, nil},
+		{"international with 00", "0084987654321", "84987654321", CARRIER_VIETTEL, nil},
+		{"zalo form", "84987654321", "84987654321", CARRIER_VIETTEL, nil},
+		{"zalo form with trunk zero", "840987654321", "84987654321", CARRIER_VIETTEL, nil},
+		{"without trunk zero", "901234567", "84901234567", CARRIER_MOBIFONE, nil},
+		{"prefix 084", "0845123456", "84845123456", CARRIER_VINAPHONE, nil},
+		{"legacy Viettel", "01681234567", "84381234567", CARRIER_VIETTEL, nil},
+		{"legacy MobiFone", "+84 121 123 4567", "84791234567", CARRIER_MOBIFONE, nil},
+		{"legacy VinaPhone", "841271234567", "84811234567", CARRIER_VINAPHONE, nil},
+		{"legacy Gmobile", "01991234567", "84591234567", CARRIER_GMOBILE, nil},
+		{"unknown carrier", "0301234567", "84301234567", CARRIER_UNKNOWN, nil},
+		{"landline", "024 3825 1234", "", "", ErrLandline},
+		{"landline international", "+842838221234", "", "", ErrLandline},
+		{"foreign", "+6591234567", "", "", ErrNotVietnamese},
+		{"foreign with 00", "0014155552671", "", "", ErrNotVietnamese},
+		{"too short", "091234567", "", "", ErrInvalid},
+		{"too long", "09123456789", "", "", ErrInvalid},
+		{"unknown legacy prefix", "01101234567", "", "", ErrInvalid},
+		{"not a mobile prefix", "0612345678", "", "", ErrInvalid},
+		{"letters", "09123abc78", "", "", ErrInvalid},
+		{"empty", "", "", "", ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, err := Parse(tt.input)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, number.String())
+			assert.Equal(t, "0"+tt.want[2:], number.National())
+			assert.Equal(t, tt.carrier, number.Carrier())
+		})
+	}
+}
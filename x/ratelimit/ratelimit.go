@@ -0,0 +1,96 @@
+// Package ratelimit implements a token bucket rate limiter.
+//
+// A bucket holds up to burst tokens and is refilled at a fixed rate.
+// Each call to Wait takes one token, blocking until a token is available
+// or the context is done. A single TokenBucket is safe for concurrent use,
+// so it can be shared by several clients that must respect the same limit.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrInvalidConfig is returned by NewTokenBucket when the rate or burst is not positive.
+var ErrInvalidConfig = errors.New("ratelimit: rate and burst must be positive")
+
+// TokenBucket is a token bucket rate limiter.
+type TokenBucket struct {
+	mu       sync.Mutex
+	rate     float64 // tokens per second
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+	now      func() time.Time
+}
+
+// NewTokenBucket creates a token bucket that allows rate events per second
+// with bursts of at most burst events. The bucket starts full.
+func NewTokenBucket(rate float64, burst int) (*TokenBucket, error) {
+	if rate <= 0 || burst <= 0 {
+		return nil, ErrInvalidConfig
+	}
+	return &TokenBucket{
+		rate:     rate,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		lastFill: time.Now(),
+		now:      time.Now,
+	}, nil
+}
+
+// Wait blocks until a token is available and takes it.
+// It returns the context error if ctx is done before a token becomes available.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay, ok := b.reserve()
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Allow takes a token if one is available without blocking and reports whether it did.
+func (b *TokenBucket) Allow() bool {
+	_, ok := b.reserve()
+	return ok
+}
+
+// reserve takes a token if one is available and reports that it did,
+// otherwise it returns how long to wait before the next token is available, at least 1ns.
+func (b *TokenBucket) reserve() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	elapsed := now.Sub(b.lastFill).Seconds()
+	if elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.lastFill = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	missing := 1 - b.tokens
+	delay := time.Duration(missing / b.rate * float64(time.Second))
+	if delay < 1 {
+		// Less than 1ns is missing at a high rate
+		delay = 1
+	}
+	return delay, false
+}
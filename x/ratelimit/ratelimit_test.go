@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenBucket(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		wantErr bool
+	}{
+		{"valid", 10, 5, false},
+		{"zero rate", 0, 5, true},
+		{"negative rate", -1, 5, true},
+		{"zero burst", 10, 0, true},
+	}
+
+	for _, tt := range tests {
+		_, err := NewTokenBucket(tt.rate, tt.burst)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidConfig, tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+		}
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	b, err := NewTokenBucket(2, 2)
+	require.NoError(t, err)
+
+	now := time.Unix(0, 0)
+	b.now = func() time.Time { return now }
+	b.lastFill = now
+
+	assert.True(t, b.Allow())
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow(), "bucket must be empty after burst")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, b.Allow(), "one token is refilled after 1/rate seconds")
+	assert.False(t, b.Allow())
+
+	now = now.Add(10 * time.Second)
+	assert.True(t, b.Allow())
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow(), "refill must not exceed burst")
+}
+
+func TestTokenBucketWaitRespectsContext(t *testing.T) {
+	b, err := NewTokenBucket(0.001, 1)
+	require.NoError(t, err)
+	require.NoError(t, b.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	b, err := NewTokenBucket(100, 1)
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.Wait(context.Background()))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+}
+
+func TestTokenBucketPartialToken(t *testing.T) {
+	b, err := NewTokenBucket(1e12, 1)
+	require.NoError(t, err)
+
+	now := time.Unix(0, 0)
+	b.now = func() time.Time { return now }
+	b.lastFill = now
+	b.tokens = 0.9999 // The missing part is refilled in less than 1ns
+
+	assert.False(t, b.Allow(), "a partial token must not be taken")
+	delay, ok := b.reserve()
+	assert.False(t, ok)
+	assert.Equal(t, time.Duration(1), delay)
+	assert.InDelta(t, 0.9999, b.tokens, 1e-9)
+}
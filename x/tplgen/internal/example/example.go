@@ -0,0 +1,34 @@
+// Package example holds code generated by tplgen for the templates of its tests.
+package example
+
+//go:generate go test .. -run TestGenerateGolden -update
+
+import "github.com/ducminhgd/zalo-go-sdk/client"
+
+// Templates are the templates templates.go is generated from.
+var Templates = []client.ZnsTplDetailData{
+	{
+		TemplateID:   100,
+		TemplateName: "Xác nhận đơn hàng",
+		Status:       client.ZNS_TPL_STATUS_ENABLED_NAME,
+		ListParams: []client.ZnsTplDetailParam{
+			{Name: "customer_name", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_STRING, MinLength: 1, MaxLength: 30},
+			{Name: "order_id", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_STRING, MaxLength: 30},
+			{Name: "cost", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_NUMBER, MaxLength: 20},
+			{Name: "total", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_CURRENCY, MaxLength: 20},
+			{Name: "order_date", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_DATE, MaxLength: 20},
+			{Name: "note", Require: false, Type: client.ZNS_TPL_PARAM_TYPE_STRING, MaxLength: 10},
+			{Name: "points", Require: false, Type: client.ZNS_TPL_PARAM_TYPE_NUMBER},
+			{Name: "delivery_date", Require: false, Type: client.ZNS_TPL_PARAM_TYPE_DATE},
+		},
+	},
+	{
+		TemplateID:   200,
+		TemplateName: "OTP",
+		Status:       client.ZNS_TPL_STATUS_ENABLED_NAME,
+		ListParams: []client.ZnsTplDetailParam{
+			{Name: "otp", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_STRING, MinLength: 6, MaxLength: 6},
+		},
+		E2EE: true,
+	},
+}
@@ -0,0 +1,63 @@
+package example
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/zalotest"
+)
+
+func TestGeneratedTemplate(t *testing.T) {
+	srv := zalotest.NewServer()
+	defer srv.Close()
+	for _, template := range Templates {
+		srv.AddTemplate(template)
+	}
+	zc := srv.Client()
+	ctx := context.Background()
+
+	points := int64(12)
+	order := XacNhanDonHangTemplate{
+		CustomerName: "Nguyễn Văn A",
+		OrderID:      "DH-0001",
+		Cost:         250000,
+		Total:        1250000,
+		OrderDate:    time.Date(2024, time.March, 9, 0, 0, 0, 0, client.VietnamLocation),
+		Points:       &points,
+	}
+	assert.Equal(t, map[string]string{
+		"customer_name": "Nguyễn Văn A",
+		"order_id":      "DH-0001",
+		"cost":          "250000",
+		"total":         "1.250.000 đ",
+		"order_date":    "09/03/2024",
+		"points":        "12",
+	}, order.ToTemplateData())
+
+	response, err := order.Send(ctx, zc, "0987654321", "")
+	require.NoError(t, err)
+	assert.Equal(t, client.SUCCESS, response.Error)
+
+	order.DeliveryDate = &time.Time{}
+	_, err = order.Send(ctx, zc, "0987654321", "")
+	assert.EqualError(t, err, "template 100: parameter delivery_date is the zero time")
+	order.DeliveryDate = nil
+
+	order.OrderDate = time.Time{}
+	_, err = order.Send(ctx, zc, "0987654321", "")
+	assert.EqualError(t, err, "template 100: parameter order_date is required")
+	order.OrderDate = time.Date(2024, time.March, 9, 0, 0, 0, 0, client.VietnamLocation)
+
+	order.Note = "more than ten characters"
+	_, err = order.Send(ctx, zc, "0987654321", "")
+	assert.EqualError(t, err, "template 100: parameter note must have up to 10 characters, got 24")
+
+	_, err = OTPTemplate{}.Send(ctx, zc, "0987654321", "")
+	assert.EqualError(t, err, "template 200: parameter otp is required")
+	assert.Len(t, srv.Messages(), 1)
+}
@@ -0,0 +1,167 @@
+// Code generated by znsgen. DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/x/znsformat"
+)
+
+// XacNhanDonHangTemplateID is the ID of the ZNS template "Xác nhận đơn hàng".
+const XacNhanDonHangTemplateID = "100"
+
+// XacNhanDonHangTemplate is the template data of the ZNS template "Xác nhận đơn hàng".
+type XacNhanDonHangTemplate struct {
+	CustomerName string     // customer_name, required, 1 to 30 characters
+	OrderID      string     // order_id, required, up to 30 characters
+	Cost         int64      // cost, required, up to 20 characters
+	Total        int64      // total, required, up to 20 characters
+	OrderDate    time.Time  // order_date, required, up to 20 characters
+	Note         string     // note, optional, up to 10 characters
+	Points       *int64     // points, optional
+	DeliveryDate *time.Time // delivery_date, optional
+}
+
+// ToTemplateData returns the template data of t.
+func (t XacNhanDonHangTemplate) ToTemplateData() map[string]string {
+	data := make(map[string]string, 8)
+	data["customer_name"] = t.CustomerName
+	data["order_id"] = t.OrderID
+	data["cost"] = strconv.FormatInt(t.Cost, 10)
+	data["total"] = znsformat.Currency(t.Total)
+	data["order_date"] = t.OrderDate.Format(client.ZNS_TPL_DATE_LAYOUT)
+	if t.Note != "" {
+		data["note"] = t.Note
+	}
+	if t.Points != nil {
+		data["points"] = strconv.FormatInt(*t.Points, 10)
+	}
+	if t.DeliveryDate != nil {
+		data["delivery_date"] = t.DeliveryDate.Format(client.ZNS_TPL_DATE_LAYOUT)
+	}
+	return data
+}
+
+// Validate checks the template data of t against the parameter constraints of the template.
+func (t XacNhanDonHangTemplate) Validate() error {
+	data := t.ToTemplateData()
+	if t.CustomerName == "" {
+		return fmt.Errorf("template 100: parameter %s is required", "customer_name")
+	}
+	if value, ok := data["customer_name"]; ok {
+		if n := utf8.RuneCountInString(value); n < 1 || n > 30 {
+			return fmt.Errorf("template 100: parameter %s must have 1 to 30 characters, got %d", "customer_name", n)
+		}
+	}
+	if t.OrderID == "" {
+		return fmt.Errorf("template 100: parameter %s is required", "order_id")
+	}
+	if value, ok := data["order_id"]; ok {
+		if n := utf8.RuneCountInString(value); n > 30 {
+			return fmt.Errorf("template 100: parameter %s must have up to 30 characters, got %d", "order_id", n)
+		}
+	}
+	if value, ok := data["cost"]; ok {
+		if n := utf8.RuneCountInString(value); n > 20 {
+			return fmt.Errorf("template 100: parameter %s must have up to 20 characters, got %d", "cost", n)
+		}
+	}
+	if value, ok := data["cost"]; ok && !znsformat.IsNumber(value) {
+		return fmt.Errorf("template 100: parameter %s is not a valid NUMBER: %q", "cost", value)
+	}
+	if value, ok := data["total"]; ok {
+		if n := utf8.RuneCountInString(value); n > 20 {
+			return fmt.Errorf("template 100: parameter %s must have up to 20 characters, got %d", "total", n)
+		}
+	}
+	if value, ok := data["total"]; ok && !znsformat.IsCurrency(value) {
+		return fmt.Errorf("template 100: parameter %s is not a valid CURRENCY: %q", "total", value)
+	}
+	if t.OrderDate.IsZero() {
+		return fmt.Errorf("template 100: parameter %s is required", "order_date")
+	}
+	if value, ok := data["order_date"]; ok {
+		if n := utf8.RuneCountInString(value); n > 20 {
+			return fmt.Errorf("template 100: parameter %s must have up to 20 characters, got %d", "order_date", n)
+		}
+	}
+	if value, ok := data["order_date"]; ok && !znsformat.IsDate(value) {
+		return fmt.Errorf("template 100: parameter %s is not a valid DATE: %q", "order_date", value)
+	}
+	if value, ok := data["note"]; ok {
+		if n := utf8.RuneCountInString(value); n > 10 {
+			return fmt.Errorf("template 100: parameter %s must have up to 10 characters, got %d", "note", n)
+		}
+	}
+	if value, ok := data["points"]; ok && !znsformat.IsNumber(value) {
+		return fmt.Errorf("template 100: parameter %s is not a valid NUMBER: %q", "points", value)
+	}
+	if t.DeliveryDate != nil && t.DeliveryDate.IsZero() {
+		return fmt.Errorf("template 100: parameter %s is the zero time", "delivery_date")
+	}
+	if value, ok := data["delivery_date"]; ok && !znsformat.IsDate(value) {
+		return fmt.Errorf("template 100: parameter %s is not a valid DATE: %q", "delivery_date", value)
+	}
+	return nil
+}
+
+// Send validates t and sends it to a phone number with zc.
+func (t XacNhanDonHangTemplate) Send(ctx context.Context, zc *client.ZaloClient, phone, trackingID string) (client.ZnsSendMsgReponse, error) {
+	if err := t.Validate(); err != nil {
+		return client.ZnsSendMsgReponse{}, err
+	}
+	return zc.SendZnsMessage(ctx, client.ZnsSendMsgRequest{
+		Phone:        phone,
+		TemplateID:   XacNhanDonHangTemplateID,
+		TemplateData: t.ToTemplateData(),
+		TrackingID:   trackingID,
+	})
+}
+
+// OTPTemplateID is the ID of the ZNS template "OTP".
+const OTPTemplateID = "200"
+
+// OTPTemplate is the template data of the ZNS template "OTP".
+type OTPTemplate struct {
+	OTP string // otp, required, exactly 6 characters
+}
+
+// ToTemplateData returns the template data of t.
+func (t OTPTemplate) ToTemplateData() map[string]string {
+	data := make(map[string]string, 1)
+	data["otp"] = t.OTP
+	return data
+}
+
+// Validate checks the template data of t against the parameter constraints of the template.
+func (t OTPTemplate) Validate() error {
+	data := t.ToTemplateData()
+	if t.OTP == "" {
+		return fmt.Errorf("template 200: parameter %s is required", "otp")
+	}
+	if value, ok := data["otp"]; ok {
+		if n := utf8.RuneCountInString(value); n < 6 || n > 6 {
+			return fmt.Errorf("template 200: parameter %s must have exactly 6 characters, got %d", "otp", n)
+		}
+	}
+	return nil
+}
+
+// Send validates t and sends it to a phone number with zc.
+func (t OTPTemplate) Send(ctx context.Context, zc *client.ZaloClient, phone, trackingID string) (client.ZnsSendMsgReponse, error) {
+	if err := t.Validate(); err != nil {
+		return client.ZnsSendMsgReponse{}, err
+	}
+	return zc.SendZnsE2EEMessage(ctx, client.ZnsSendMsgRequest{
+		Phone:        phone,
+		TemplateID:   OTPTemplateID,
+		TemplateData: t.ToTemplateData(),
+		TrackingID:   trackingID,
+	})
+}
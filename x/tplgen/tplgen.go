@@ -0,0 +1,371 @@
+// Package tplgen generates typed Go code for ZNS templates from their detail.
+//
+// For each template, Generate emits a struct with one typed field per template parameter,
+// a ToTemplateData method building the map expected by client.ZnsSendMsgRequest, a Validate
+// method checking the parameter constraints, and a Send method. Generated code only depends
+// on the client and znsformat packages and the standard library, so a parameter renamed or
+// removed in a template breaks the build of the code using it once the code is generated again.
+//
+// NUMBER parameters are int64, CURRENCY parameters are int64 amounts of VND formatted with
+// znsformat.Currency, e.g. "250.000 đ", and DATE parameters are time.Time formatted with
+// client.ZNS_TPL_DATE_LAYOUT; other parameters are strings. Optional NUMBER, CURRENCY and
+// DATE parameters are pointers, and optional parameters are omitted from the template data
+// when nil or empty. Validate also checks the format of NUMBER, CURRENCY and DATE values
+// with znsformat, like Zalo does, and that DATE values are not the zero time.
+package tplgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+// ErrNoTemplates is returned by Generate when called without templates.
+var ErrNoTemplates = errors.New("tplgen: no templates")
+
+// Options configures Generate.
+type Options struct {
+	Package string // Name of the generated package, default "templates"
+	Command string // Command line mentioned in the header of the generated file, default "znsgen"
+}
+
+// Generate returns the formatted Go source of the typed code of templates, sorted by template ID.
+func Generate(options Options, templates ...client.ZnsTplDetailData) ([]byte, error) {
+	if len(templates) == 0 {
+		return nil, ErrNoTemplates
+	}
+	if options.Package == "" {
+		options.Package = "templates"
+	}
+	if options.Command == "" {
+		options.Command = "znsgen"
+	}
+
+	templates = append([]client.ZnsTplDetailData(nil), templates...)
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].TemplateID < templates[j].TemplateID
+	})
+
+	g := &generator{names: make(map[string]bool)}
+	var body bytes.Buffer
+	for _, template := range templates {
+		g.template(&body, template)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by %s. DO NOT EDIT.\n\n", options.Command)
+	fmt.Fprintf(&out, "package %s\n\nimport (\n\t\"context\"\n", options.Package)
+	for _, pkg := range []string{"fmt", "strconv", "time", "unicode/utf8"} {
+		if g.imports[pkg] {
+			fmt.Fprintf(&out, "\t%q\n", pkg)
+		}
+	}
+	fmt.Fprintf(&out, "\n\t\"github.com/ducminhgd/zalo-go-sdk/client\"\n")
+	if g.imports["znsformat"] {
+		fmt.Fprintf(&out, "\t\"github.com/ducminhgd/zalo-go-sdk/x/znsformat\"\n")
+	}
+	fmt.Fprintf(&out, ")\n")
+	out.Write(body.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+// generator holds the state shared by the templates of a generated file.
+type generator struct {
+	names   map[string]bool // Type names already used
+	imports map[string]bool
+}
+
+// field is a template parameter and its Go field.
+type field struct {
+	param    client.ZnsTplDetailParam
+	name     string
+	goType   string
+	pointer  bool // Optional NUMBER, CURRENCY or DATE parameter
+	valueOf  string
+	optional bool
+}
+
+func (g *generator) use(pkg string) {
+	if g.imports == nil {
+		g.imports = make(map[string]bool)
+	}
+	g.imports[pkg] = true
+}
+
+func (g *generator) template(w *bytes.Buffer, template client.ZnsTplDetailData) {
+	id := strconv.Itoa(template.TemplateID)
+	name := identifier(template.TemplateName) + "Template"
+	if !unicode.IsLetter(rune(name[0])) {
+		// e.g. "2FA OTP"
+		name = "Template" + strings.TrimSuffix(name, "Template")
+	}
+	if name == "Template" || g.names[name] {
+		name = "Template" + id
+	}
+	g.names[name] = true
+
+	fields := g.fields(template.ListParams)
+	label := strconv.Quote(template.TemplateName)
+
+	fmt.Fprintf(w, "\n// %sID is the ID of the ZNS template %s.\n", name, label)
+	fmt.Fprintf(w, "const %sID = %q\n", name, id)
+
+	fmt.Fprintf(w, "\n// %s is the template data of the ZNS template %s.\n", name, label)
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(w, "\t%s %s // %s\n", f.name, f.goType, describe(f.param))
+	}
+	fmt.Fprintf(w, "}\n")
+
+	fmt.Fprintf(w, "\n// ToTemplateData returns the template data of t.\n")
+	fmt.Fprintf(w, "func (t %s) ToTemplateData() map[string]string {\n", name)
+	fmt.Fprintf(w, "\tdata := make(map[string]string, %d)\n", len(fields))
+	for _, f := range fields {
+		key := strconv.Quote(f.param.Name)
+		switch {
+		case f.pointer:
+			fmt.Fprintf(w, "\tif t.%s != nil {\n\t\tdata[%s] = %s\n\t}\n", f.name, key, f.valueOf)
+		case f.optional:
+			fmt.Fprintf(w, "\tif t.%s != \"\" {\n\t\tdata[%s] = t.%s\n\t}\n", f.name, key, f.name)
+		default:
+			fmt.Fprintf(w, "\tdata[%s] = %s\n", key, f.valueOf)
+		}
+	}
+	fmt.Fprintf(w, "\treturn data\n}\n")
+
+	fmt.Fprintf(w, "\n// Validate checks the template data of t against the parameter constraints of the template.\n")
+	fmt.Fprintf(w, "func (t %s) Validate() error {\n", name)
+	for _, f := range fields {
+		if hasLength(f.param) || formatCheck[f.param.Type] != "" {
+			fmt.Fprintf(w, "\tdata := t.ToTemplateData()\n")
+			break
+		}
+	}
+	for _, f := range fields {
+		g.check(w, f, id)
+	}
+	fmt.Fprintf(w, "\treturn nil\n}\n")
+
+	send := "SendZnsMessage"
+	if template.E2EE {
+		send = "SendZnsE2EEMessage"
+	}
+	fmt.Fprintf(w, "\n// Send validates t and sends it to a phone number with zc.\n")
+	fmt.Fprintf(w, "func (t %s) Send(ctx context.Context, zc *client.ZaloClient, phone, trackingID string) (client.ZnsSendMsgReponse, error) {\n", name)
+	fmt.Fprintf(w, "\tif err := t.Validate(); err != nil {\n\t\treturn client.ZnsSendMsgReponse{}, err\n\t}\n")
+	fmt.Fprintf(w, "\treturn zc.%s(ctx, client.ZnsSendMsgRequest{\n", send)
+	fmt.Fprintf(w, "\t\tPhone:        phone,\n\t\tTemplateID:   %sID,\n\t\tTemplateData: t.ToTemplateData(),\n\t\tTrackingID:   trackingID,\n\t})\n}\n", name)
+}
+
+// reserved are the names of the generated methods, which fields cannot have.
+var reserved = map[string]bool{"ToTemplateData": true, "Validate": true, "Send": true}
+
+// formatCheck are the znsformat functions checking the values of parameter types.
+var formatCheck = map[string]string{
+	client.ZNS_TPL_PARAM_TYPE_NUMBER:   "IsNumber",
+	client.ZNS_TPL_PARAM_TYPE_DATE:     "IsDate",
+	client.ZNS_TPL_PARAM_TYPE_CURRENCY: "IsCurrency",
+}
+
+// fields returns the Go fields of template parameters.
+func (g *generator) fields(params []client.ZnsTplDetailParam) []field {
+	fields := make([]field, 0, len(params))
+	used := make(map[string]bool)
+	for i, param := range params {
+		f := field{param: param, name: identifier(param.Name), optional: !param.Require}
+		if f.name == "" || !unicode.IsLetter(rune(f.name[0])) || reserved[f.name] {
+			f.name = "Param" + f.name
+		}
+		for base, n := f.name, i+1; used[f.name]; n++ {
+			f.name = base + strconv.Itoa(n)
+		}
+		used[f.name] = true
+
+		value := "t." + f.name
+		switch param.Type {
+		case client.ZNS_TPL_PARAM_TYPE_NUMBER:
+			g.use("strconv")
+			f.goType = "int64"
+			if f.optional {
+				f.goType, f.pointer, value = "*int64", true, "*"+value
+			}
+			f.valueOf = "strconv.FormatInt(" + value + ", 10)"
+		case client.ZNS_TPL_PARAM_TYPE_CURRENCY:
+			g.use("znsformat")
+			f.goType = "int64"
+			if f.optional {
+				f.goType, f.pointer, value = "*int64", true, "*"+value
+			}
+			f.valueOf = "znsformat.Currency(" + value + ")"
+		case client.ZNS_TPL_PARAM_TYPE_DATE:
+			g.use("time")
+			f.goType = "time.Time"
+			if f.optional {
+				f.goType, f.pointer = "*time.Time", true
+			}
+			f.valueOf = value + ".Format(client.ZNS_TPL_DATE_LAYOUT)"
+		default:
+			f.goType = "string"
+			f.valueOf = value
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// check writes the validation of a field.
+func (g *generator) check(w *bytes.Buffer, f field, templateID string) {
+	key := strconv.Quote(f.param.Name)
+	if f.goType == "string" && f.param.Require && !f.param.AcceptNull {
+		g.use("fmt")
+		fmt.Fprintf(w, "\tif t.%s == \"\" {\n", f.name)
+		fmt.Fprintf(w, "\t\treturn fmt.Errorf(\"template %s: parameter %%s is required\", %s)\n\t}\n", templateID, key)
+	}
+	if f.param.Type == client.ZNS_TPL_PARAM_TYPE_DATE {
+		g.use("fmt")
+		if f.pointer {
+			fmt.Fprintf(w, "\tif t.%s != nil && t.%s.IsZero() {\n", f.name, f.name)
+			fmt.Fprintf(w, "\t\treturn fmt.Errorf(\"template %s: parameter %%s is the zero time\", %s)\n\t}\n", templateID, key)
+		} else {
+			fmt.Fprintf(w, "\tif t.%s.IsZero() {\n", f.name)
+			fmt.Fprintf(w, "\t\treturn fmt.Errorf(\"template %s: parameter %%s is required\", %s)\n\t}\n", templateID, key)
+		}
+	}
+	if hasLength(f.param) {
+		g.use("fmt")
+		g.use("unicode/utf8")
+		fmt.Fprintf(w, "\tif value, ok := data[%s]; ok {\n", key)
+		fmt.Fprintf(w, "\t\tif n := utf8.RuneCountInString(value); ")
+		switch {
+		case f.param.MinLength > 0 && f.param.MaxLength > 0:
+			fmt.Fprintf(w, "n < %d || n > %d {\n", f.param.MinLength, f.param.MaxLength)
+		case f.param.MaxLength > 0:
+			fmt.Fprintf(w, "n > %d {\n", f.param.MaxLength)
+		default:
+			fmt.Fprintf(w, "n < %d {\n", f.param.MinLength)
+		}
+		fmt.Fprintf(w, "\t\t\treturn fmt.Errorf(\"template %s: parameter %%s must have %s, got %%d\", %s, n)\n\t\t}\n\t}\n",
+			templateID, lengthRange(f.param), key)
+	}
+	if check := formatCheck[f.param.Type]; check != "" {
+		g.use("fmt")
+		g.use("znsformat")
+		fmt.Fprintf(w, "\tif value, ok := data[%s]; ok && !znsformat.%s(value) {\n", key, check)
+		fmt.Fprintf(w, "\t\treturn fmt.Errorf(\"template %s: parameter %%s is not a valid %s: %%q\", %s, value)\n\t}\n",
+			templateID, f.param.Type, key)
+	}
+}
+
+// describe returns the comment of a field.
+func describe(param client.ZnsTplDetailParam) string {
+	parts := []string{param.Name}
+	if param.Require {
+		parts = append(parts, "required")
+	} else {
+		parts = append(parts, "optional")
+	}
+	if hasLength(param) {
+		parts = append(parts, lengthRange(param))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func hasLength(param client.ZnsTplDetailParam) bool {
+	return param.MaxLength > 0 || param.MinLength > 0
+}
+
+func lengthRange(param client.ZnsTplDetailParam) string {
+	switch {
+	case param.MinLength == param.MaxLength:
+		return fmt.Sprintf("exactly %d characters", param.MaxLength)
+	case param.MinLength > 0 && param.MaxLength > 0:
+		return fmt.Sprintf("%d to %d characters", param.MinLength, param.MaxLength)
+	case param.MaxLength > 0:
+		return fmt.Sprintf("up to %d characters", param.MaxLength)
+	default:
+		return fmt.Sprintf("at least %d characters", param.MinLength)
+	}
+}
+
+// initialisms are the words written in upper case in identifiers.
+var initialisms = map[string]bool{"id": true, "otp": true, "url": true}
+
+// identifier converts a template or parameter name, possibly in Vietnamese, to an exported Go identifier.
+func identifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range fold(name) {
+		if !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	// Upper case initialisms, e.g. OrderId becomes OrderID
+	words := splitWords(b.String())
+	for i, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			words[i] = strings.ToUpper(word)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits a camel case identifier before each upper case letter following a lower case letter or digit.
+func splitWords(s string) []string {
+	var words []string
+	start := 0
+	for i := 1; i < len(s); i++ {
+		if unicode.IsUpper(rune(s[i])) && !unicode.IsUpper(rune(s[i-1])) {
+			words = append(words, s[start:i])
+			start = i
+		}
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// vietnamese maps the Vietnamese letters with diacritics to their base letter.
+var vietnamese = map[rune]rune{}
+
+func init() {
+	for base, letters := range map[rune]string{
+		'a': "àáảãạăằắẳẵặâầấẩẫậ",
+		'e': "èéẻẽẹêềếểễệ",
+		'i': "ìíỉĩị",
+		'o': "òóỏõọôồốổỗộơờớởỡợ",
+		'u': "ùúủũụưừứửữự",
+		'y': "ỳýỷỹỵ",
+		'd': "đ",
+	} {
+		for _, r := range letters {
+			vietnamese[r] = base
+			vietnamese[unicode.ToUpper(r)] = unicode.ToUpper(base)
+		}
+	}
+}
+
+// fold removes the Vietnamese diacritics of s.
+func fold(s string) string {
+	return strings.Map(func(r rune) rune {
+		if base, ok := vietnamese[r]; ok {
+			return base
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,84 @@
+package tplgen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/x/tplgen/internal/example"
+)
+
+var update = flag.Bool("update", false, "update the generated example")
+
+func TestGenerateGolden(t *testing.T) {
+	source, err := Generate(Options{Package: "example"}, example.Templates...)
+	require.NoError(t, err)
+
+	path := filepath.Join("internal", "example", "templates.go")
+	if *update {
+		require.NoError(t, os.WriteFile(path, source, 0o644))
+	}
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(source), "run go generate ./x/tplgen/... to update")
+}
+
+func TestGenerate(t *testing.T) {
+	_, err := Generate(Options{})
+	assert.ErrorIs(t, err, ErrNoTemplates)
+
+	source, err := Generate(Options{}, client.ZnsTplDetailData{
+		TemplateID:   300,
+		TemplateName: "Thông báo",
+		ListParams: []client.ZnsTplDetailParam{
+			{Name: "1st", Type: client.ZNS_TPL_PARAM_TYPE_STRING},
+			{Name: "first_name", Type: client.ZNS_TPL_PARAM_TYPE_STRING},
+			{Name: "firstName", Type: client.ZNS_TPL_PARAM_TYPE_STRING},
+			{Name: "validate", Type: client.ZNS_TPL_PARAM_TYPE_STRING},
+			{Name: "send", Type: client.ZNS_TPL_PARAM_TYPE_STRING},
+		},
+	}, client.ZnsTplDetailData{
+		TemplateID:   303,
+		TemplateName: "Lịch hẹn",
+		ListParams: []client.ZnsTplDetailParam{
+			{Name: "name", Type: client.ZNS_TPL_PARAM_TYPE_STRING},
+			{Name: "name3", Type: client.ZNS_TPL_PARAM_TYPE_STRING},
+			{Name: "Name", Type: client.ZNS_TPL_PARAM_TYPE_STRING},
+		},
+	}, client.ZnsTplDetailData{TemplateID: 301, TemplateName: "Thông báo!"},
+		client.ZnsTplDetailData{TemplateID: 302, TemplateName: "2FA OTP"})
+	require.NoError(t, err)
+
+	code := string(source)
+	assert.Contains(t, code, "package templates")
+	assert.Contains(t, code, "type ThongBaoTemplate struct")
+	assert.Contains(t, code, "type Template301 struct", "duplicate names fall back to the template ID")
+	assert.Contains(t, code, "Param1st ")
+	assert.Contains(t, code, `data["first_name"] = t.FirstName`)
+	assert.Contains(t, code, `data["firstName"] = t.FirstName3`, "duplicate fields are numbered")
+	assert.Contains(t, code, `data["Name"] = t.Name4`, "numbered fields do not collide with other fields")
+	assert.Contains(t, code, `data["validate"] = t.ParamValidate`, "fields do not collide with methods")
+	assert.Contains(t, code, `data["send"] = t.ParamSend`)
+	assert.Contains(t, code, "type Template2FAOTP struct", "type names start with a letter")
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"customer_name":     "CustomerName",
+		"customerName":      "CustomerName",
+		"order_id":          "OrderID",
+		"Mã OTP":            "MaOTP",
+		"Đơn hàng đã giao":  "DonHangDaGiao",
+		"  ":                "",
+		"thời gian (giờ)":   "ThoiGianGio",
+		"url_theo_dõi_2024": "URLTheoDoi2024",
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, identifier(input), input)
+	}
+}
@@ -0,0 +1,120 @@
+// Package znsformat formats values as ZNS template parameters and checks formatted parameters.
+//
+// Numbers use the Vietnamese grouping, with a dot between thousands and a comma before decimals,
+// e.g. Number(10000) is "10.000" and Decimal(1234.5, 2) is "1.234,50". Dates are formatted in
+// Vietnam time. The Is functions accept the values produced by the formatters as well as the
+// forms commonly written by hand, e.g. "10,000" for a NUMBER parameter.
+package znsformat
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DATE_LAYOUT      = "02/01/2006"          // e.g. 09/03/2024
+	DATE_TIME_LAYOUT = "15:04:05 02/01/2006" // e.g. 14:30:00 09/03/2024
+
+	CURRENCY_SUFFIX = "đ" // Suffix of amounts formatted by Currency
+)
+
+// VietnamLocation is the Asia/Ho_Chi_Minh time zone, UTC+7 without daylight saving time,
+// where dates are formatted. It is also client.VietnamLocation.
+var VietnamLocation = time.FixedZone("Asia/Ho_Chi_Minh", 7*60*60)
+
+// Date formats the date of t in Vietnam time.
+func Date(t time.Time) string {
+	return t.In(VietnamLocation).Format(DATE_LAYOUT)
+}
+
+// DateTime formats the time and date of t in Vietnam time.
+func DateTime(t time.Time) string {
+	return t.In(VietnamLocation).Format(DATE_TIME_LAYOUT)
+}
+
+// Number formats an integer with the Vietnamese thousands separator, e.g. 10000 as "10.000".
+func Number(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	return sign + group(digits)
+}
+
+// Decimal formats an amount rounded to decimals digits after the decimal comma,
+// e.g. Decimal(1234.5, 2) as "1.234,50". NaN and infinite amounts are formatted as "0".
+func Decimal(amount float64, decimals int) string {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return "0"
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', decimals, 64)
+	sign := ""
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		sign = "-"
+	}
+
+	integer, fraction, _ := strings.Cut(digits, ".")
+	if fraction != "" {
+		return sign + group(integer) + "," + fraction
+	}
+	return sign + group(integer)
+}
+
+// Currency formats an amount of VND with the currency suffix, e.g. 10000 as "10.000 đ".
+func Currency(amount int64) string {
+	return Number(amount) + " " + CURRENCY_SUFFIX
+}
+
+// group inserts the thousands separator in a string of digits.
+func group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+var (
+	number   = regexp.MustCompile(`^-?(\d+([.,]\d+)?|\d{1,3}(\.\d{3})+(,\d+)?|\d{1,3}(,\d{3})+(\.\d+)?)$`)
+	currency = regexp.MustCompile(`^(.+?)\s*(đ|đồng|VNĐ|VND)$`)
+)
+
+// IsNumber reports whether s is a number, with or without thousands separators.
+func IsNumber(s string) bool {
+	return number.MatchString(s)
+}
+
+// IsDate reports whether s is a date, with or without time, in the layouts accepted by ZNS.
+func IsDate(s string) bool {
+	for _, layout := range []string{DATE_LAYOUT, DATE_TIME_LAYOUT, "15:04 02/01/2006"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCurrency reports whether s is an amount, optionally followed by a VND currency suffix.
+func IsCurrency(s string) bool {
+	if match := currency.FindStringSubmatch(s); match != nil {
+		s = match[1]
+	}
+	return IsNumber(s)
+}
@@ -0,0 +1,69 @@
+package znsformat
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	at := time.Date(2024, time.March, 9, 18, 30, 5, 0, time.UTC) // 01:30:05 on March 10 in Vietnam
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"date", Date(at), "10/03/2024"},
+		{"date time", DateTime(at), "01:30:05 10/03/2024"},
+		{"small number", Number(999), "999"},
+		{"number", Number(10000), "10.000"},
+		{"large number", Number(1234567890), "1.234.567.890"},
+		{"negative number", Number(-1234), "-1.234"},
+		{"decimal", Decimal(1234.5, 2), "1.234,50"},
+		{"decimal rounded", Decimal(0.126, 2), "0,13"},
+		{"decimal without decimals", Decimal(1234567.8, 0), "1.234.568"},
+		{"negative decimal", Decimal(-1234.5, 1), "-1.234,5"},
+		{"negative zero", Decimal(-0.001, 2), "0,00"},
+		{"NaN", Decimal(math.NaN(), 2), "0"},
+		{"currency", Currency(10000), "10.000 đ"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.got, tt.name)
+	}
+}
+
+func TestIs(t *testing.T) {
+	tests := []struct {
+		check func(string) bool
+		value string
+		want  bool
+	}{
+		{IsNumber, "10000", true},
+		{IsNumber, "10.000", true},
+		{IsNumber, "10,000", true},
+		{IsNumber, "1.234,50", true},
+		{IsNumber, "1,234.50", true},
+		{IsNumber, "-12,5", true},
+		{IsNumber, "1.234.5", false},
+		{IsNumber, "10.00.000", false},
+		{IsNumber, "10 000", false},
+		{IsNumber, "mười", false},
+		{IsNumber, "", false},
+		{IsDate, "09/03/2024", true},
+		{IsDate, "14:30:00 09/03/2024", true},
+		{IsDate, "14:30 09/03/2024", true},
+		{IsDate, "2024-03-09", false},
+		{IsDate, "31/02/2024", false},
+		{IsCurrency, "10.000 đ", true},
+		{IsCurrency, "10,000 đồng", true},
+		{IsCurrency, "10.000VNĐ", true},
+		{IsCurrency, "10000", true},
+		{IsCurrency, "10.000 USD", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.check(tt.value), tt.value)
+	}
+}
@@ -0,0 +1,231 @@
+// Package znspreview renders a local preview of a ZNS message from the layout of its template
+// and its template data, so that a message can be reviewed with real data before it is sent.
+//
+// Render returns the message as plain text and as an HTML fragment, along with the issues of
+// the template data: missing parameters, values too short or too long, values in the wrong
+// format and parameters the template does not define. Issues are found with the checks of
+// client.ZnsTplDetailData.ValidateTemplateData, so a message without issue passes it. A parameter
+// used by the layout or set in the data but missing from the parameters of the template is
+// undefined, even without value. Parameters with an issue are highlighted in both renderings:
+// in plain text with a bracketed note after the value, e.g. "[too long: 24/10]", or instead of
+// a missing value, e.g. "[missing: otp]", and in HTML with a mark element of the classes
+// "zns-issue" and "zns-<kind>", e.g. "zns-too-long", titled with the issue message.
+//
+// The logo of the header is not rendered.
+package znspreview
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+// ErrNoLayout is returned by Render for a template without layout.
+var ErrNoLayout = errors.New("znspreview: template has no layout")
+
+// IssueKind is the kind of an issue of template data.
+type IssueKind string
+
+const (
+	ISSUE_MISSING        IssueKind = "missing"        // Required parameter missing or empty
+	ISSUE_TOO_SHORT      IssueKind = "too-short"      // Value shorter than the minimum length
+	ISSUE_TOO_LONG       IssueKind = "too-long"       // Value longer than the maximum length
+	ISSUE_INVALID_FORMAT IssueKind = "invalid-format" // NUMBER, DATE or CURRENCY value in the wrong format
+	ISSUE_UNDEFINED      IssueKind = "undefined"      // Parameter not defined by the template
+)
+
+// Issue is a problem of the template data, which Zalo would reject.
+type Issue struct {
+	Param   string
+	Kind    IssueKind
+	Message string
+}
+
+// Preview is the rendering of a message.
+type Preview struct {
+	Text   string
+	HTML   string
+	Issues []Issue // Issues in order of the template parameters, then undefined parameters by name
+}
+
+// OK reports whether the template data has no issue.
+func (p Preview) OK() bool {
+	return len(p.Issues) == 0
+}
+
+// Render renders the message of template with data.
+func Render(template client.ZnsTplDetailData, data map[string]string) (Preview, error) {
+	if template.Layout == nil {
+		return Preview{}, ErrNoLayout
+	}
+
+	params := make(map[string]client.ZnsTplDetailParam, len(template.ListParams))
+	for _, param := range template.ListParams {
+		params[param.Name] = param
+	}
+
+	var preview Preview
+	issues := make(map[string]Issue)
+	for _, param := range template.ListParams {
+		value, ok := data[param.Name]
+		if issue, found := check(param, value, ok); found {
+			issues[param.Name] = issue
+			preview.Issues = append(preview.Issues, issue)
+		}
+	}
+	undefined := make(map[string]bool)
+	for _, name := range template.Layout.Params() {
+		if _, ok := params[name]; !ok {
+			undefined[name] = true
+		}
+	}
+	for name := range data {
+		if _, ok := params[name]; !ok {
+			undefined[name] = true
+		}
+	}
+	names := make([]string, 0, len(undefined))
+	for name := range undefined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		issue := Issue{Param: name, Kind: ISSUE_UNDEFINED, Message: fmt.Sprintf("parameter %s is not defined by the template", name)}
+		issues[name] = issue
+		preview.Issues = append(preview.Issues, issue)
+	}
+
+	r := renderer{data: data, params: params, issues: issues}
+	preview.Text, preview.HTML = r.render(*template.Layout)
+	return preview, nil
+}
+
+// check returns the issue of the value of a parameter found by client.ZnsTplDetailParam.Check, if any.
+func check(param client.ZnsTplDetailParam, value string, ok bool) (Issue, bool) {
+	var znsErr *client.ZnsError
+	if err := param.Check(value, ok); !errors.As(err, &znsErr) {
+		return Issue{}, false
+	}
+
+	length := utf8.RuneCountInString(value)
+	switch {
+	case znsErr.Code == client.TEMPLATE_DATA_MISSING_PARAMETER_NAME:
+		return Issue{Param: param.Name, Kind: ISSUE_MISSING, Message: fmt.Sprintf("parameter %s is required", param.Name)}, true
+	case znsErr.Code == client.PARAMETER_NAME_DATA_BREAKS_LENGTH && param.MaxLength > 0 && length > param.MaxLength:
+		return Issue{Param: param.Name, Kind: ISSUE_TOO_LONG, Message: fmt.Sprintf("parameter %s has %d characters, up to %d allowed", param.Name, length, param.MaxLength)}, true
+	case znsErr.Code == client.PARAMETER_NAME_DATA_BREAKS_LENGTH:
+		return Issue{Param: param.Name, Kind: ISSUE_TOO_SHORT, Message: fmt.Sprintf("parameter %s has %d characters, at least %d required", param.Name, length, param.MinLength)}, true
+	default:
+		return Issue{Param: param.Name, Kind: ISSUE_INVALID_FORMAT, Message: fmt.Sprintf("parameter %s is not a valid %s", param.Name, param.Type)}, true
+	}
+}
+
+type renderer struct {
+	data   map[string]string
+	params map[string]client.ZnsTplDetailParam
+	issues map[string]Issue
+}
+
+// render returns the plain text and the HTML of a layout.
+func (r renderer) render(layout client.ZnsTplLayout) (string, string) {
+	var blocks []string
+	var h strings.Builder
+	h.WriteString(`<div class="zns-preview">`)
+	for _, section := range []client.ZnsTplLayoutSection{layout.Header, layout.Body, layout.Footer} {
+		for _, c := range section.Components {
+			switch {
+			case c.Title != nil:
+				blocks = append(blocks, r.text(c.Title.Value))
+				fmt.Fprintf(&h, `<h1 class="zns-title">%s</h1>`, r.html(c.Title.Value))
+			case c.Paragraph != nil:
+				blocks = append(blocks, r.text(c.Paragraph.Value))
+				fmt.Fprintf(&h, `<p class="zns-paragraph">%s</p>`, r.html(c.Paragraph.Value))
+			case c.Table != nil:
+				lines := make([]string, 0, len(c.Table.Rows))
+				h.WriteString(`<table class="zns-table">`)
+				for _, row := range c.Table.Rows {
+					lines = append(lines, r.text(row.Title)+": "+r.text(row.Value))
+					fmt.Fprintf(&h, `<tr><th>%s</th><td>%s</td></tr>`, r.html(row.Title), r.html(row.Value))
+				}
+				h.WriteString(`</table>`)
+				blocks = append(blocks, strings.Join(lines, "\n"))
+			case c.Buttons != nil:
+				lines := make([]string, 0, len(c.Buttons.Items))
+				h.WriteString(`<div class="zns-buttons">`)
+				for _, button := range c.Buttons.Items {
+					lines = append(lines, "["+r.text(button.Title)+"] "+r.text(button.Content))
+					fmt.Fprintf(&h, `<div class="zns-button">%s<span class="zns-button-content">%s</span></div>`, r.html(button.Title), r.html(button.Content))
+				}
+				h.WriteString(`</div>`)
+				blocks = append(blocks, strings.Join(lines, "\n"))
+			}
+		}
+	}
+	h.WriteString(`</div>`)
+	return strings.Join(blocks, "\n\n"), h.String()
+}
+
+// text returns a layout text with its parameters replaced by their values.
+func (r renderer) text(text string) string {
+	return client.ReplaceParams(text, func(name string) string {
+		value := r.data[name]
+		issue, ok := r.issues[name]
+		switch {
+		case !ok:
+			return value
+		case value == "":
+			return "[" + strings.ReplaceAll(string(issue.Kind), "-", " ") + ": " + name + "]"
+		default:
+			return value + " [" + r.note(issue, value) + "]"
+		}
+	})
+}
+
+// html returns a layout text as HTML with its parameters replaced by their values.
+func (r renderer) html(text string) string {
+	var b strings.Builder
+	rest := text
+	client.ReplaceParams(text, func(name string) string {
+		// Matches are visited in order, rest starts after the previous one
+		i := strings.Index(rest, "<"+name+">")
+		b.WriteString(escape(rest[:i]))
+		rest = rest[i+len(name)+2:]
+
+		value := r.data[name]
+		issue, ok := r.issues[name]
+		switch {
+		case !ok:
+			b.WriteString(escape(value))
+		case value == "":
+			fmt.Fprintf(&b, `<mark class="zns-issue zns-%s" title="%s">%s</mark>`, issue.Kind, html.EscapeString(issue.Message), html.EscapeString("<"+name+">"))
+		default:
+			fmt.Fprintf(&b, `<mark class="zns-issue zns-%s" title="%s">%s</mark>`, issue.Kind, html.EscapeString(issue.Message), escape(value))
+		}
+		return ""
+	})
+	b.WriteString(escape(rest))
+	return b.String()
+}
+
+// note returns the bracketed note of an issue in plain text, e.g. "too long: 24/10".
+func (r renderer) note(issue Issue, value string) string {
+	kind := strings.ReplaceAll(string(issue.Kind), "-", " ")
+	switch issue.Kind {
+	case ISSUE_TOO_LONG:
+		return fmt.Sprintf("%s: %d/%d", kind, utf8.RuneCountInString(value), r.params[issue.Param].MaxLength)
+	case ISSUE_TOO_SHORT:
+		return fmt.Sprintf("%s: %d/%d", kind, utf8.RuneCountInString(value), r.params[issue.Param].MinLength)
+	default:
+		return kind
+	}
+}
+
+// escape escapes text for HTML, keeping its line breaks.
+func escape(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
@@ -0,0 +1,129 @@
+package znspreview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+var template = client.ZnsTplDetailData{
+	TemplateID:   100,
+	TemplateName: "Xác nhận đơn hàng",
+	ListParams: []client.ZnsTplDetailParam{
+		{Name: "customer_name", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_STRING, MaxLength: 10},
+		{Name: "order_code", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_STRING, MinLength: 6, MaxLength: 6},
+		{Name: "cost", Require: true, Type: client.ZNS_TPL_PARAM_TYPE_NUMBER, MaxLength: 20},
+		{Name: "note", Require: false, Type: client.ZNS_TPL_PARAM_TYPE_STRING, MaxLength: 30},
+	},
+	Layout: &client.ZnsTplLayout{
+		Body: client.ZnsTplLayoutSection{Components: []client.ZnsTplComponent{
+			{Title: &client.ZnsTplText{Value: "Xác nhận đơn hàng <order_code>"}},
+			{Paragraph: &client.ZnsTplText{Value: "Chào <customer_name>,\nđơn hàng của bạn đã được xác nhận."}},
+			{Table: &client.ZnsTplTable{Rows: []client.ZnsTplTableRow{
+				{Title: "Mã đơn", Value: "<order_code>"},
+				{Title: "Tổng tiền", Value: "<cost> đ"},
+				{Title: "Ghi chú", Value: "<note>"},
+			}}},
+		}},
+		Footer: client.ZnsTplLayoutSection{Components: []client.ZnsTplComponent{
+			{Buttons: &client.ZnsTplButtons{Items: []client.ZnsTplButton{
+				{Type: client.ZNS_TPL_BUTTON_URL, Title: "Xem đơn", Content: "https://example.com/orders/<order_code>"},
+			}}},
+		}},
+	},
+}
+
+func TestRender(t *testing.T) {
+	preview, err := Render(template, map[string]string{
+		"customer_name": "Lan & Huệ",
+		"order_code":    "A12345",
+		"cost":          "250.000",
+	})
+	require.NoError(t, err)
+	assert.True(t, preview.OK())
+	assert.Equal(t, "Xác nhận đơn hàng A12345\n\n"+
+		"Chào Lan & Huệ,\nđơn hàng của bạn đã được xác nhận.\n\n"+
+		"Mã đơn: A12345\nTổng tiền: 250.000 đ\nGhi chú: \n\n"+
+		"[Xem đơn] https://example.com/orders/A12345", preview.Text)
+	assert.Equal(t, `<div class="zns-preview">`+
+		`<h1 class="zns-title">Xác nhận đơn hàng A12345</h1>`+
+		`<p class="zns-paragraph">Chào Lan &amp; Huệ,<br>đơn hàng của bạn đã được xác nhận.</p>`+
+		`<table class="zns-table"><tr><th>Mã đơn</th><td>A12345</td></tr><tr><th>Tổng tiền</th><td>250.000 đ</td></tr><tr><th>Ghi chú</th><td></td></tr></table>`+
+		`<div class="zns-buttons"><div class="zns-button">Xem đơn<span class="zns-button-content">https://example.com/orders/A12345</span></div></div>`+
+		`</div>`, preview.HTML)
+}
+
+func TestRenderIssues(t *testing.T) {
+	preview, err := Render(template, map[string]string{
+		"customer_name": "Nguyễn Thị Lan",
+		"order_code":    "",
+		"cost":          "250k",
+		"discount":      "10",
+	})
+	require.NoError(t, err)
+	assert.False(t, preview.OK())
+	assert.Equal(t, []Issue{
+		{Param: "customer_name", Kind: ISSUE_TOO_LONG, Message: "parameter customer_name has 14 characters, up to 10 allowed"},
+		{Param: "order_code", Kind: ISSUE_MISSING, Message: "parameter order_code is required"},
+		{Param: "cost", Kind: ISSUE_INVALID_FORMAT, Message: "parameter cost is not a valid NUMBER"},
+		{Param: "discount", Kind: ISSUE_UNDEFINED, Message: "parameter discount is not defined by the template"},
+	}, preview.Issues)
+
+	assert.Contains(t, preview.Text, "Xác nhận đơn hàng [missing: order_code]")
+	assert.Contains(t, preview.Text, "Chào Nguyễn Thị Lan [too long: 14/10],")
+	assert.Contains(t, preview.Text, "Tổng tiền: 250k [invalid format] đ")
+	assert.Contains(t, preview.HTML, `<mark class="zns-issue zns-missing" title="parameter order_code is required">&lt;order_code&gt;</mark>`)
+	assert.Contains(t, preview.HTML, `Chào <mark class="zns-issue zns-too-long" title="parameter customer_name has 14 characters, up to 10 allowed">Nguyễn Thị Lan</mark>,<br>`)
+
+	preview, err = Render(template, map[string]string{"customer_name": "Lan", "order_code": "A123", "cost": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, []Issue{{Param: "order_code", Kind: ISSUE_TOO_SHORT, Message: "parameter order_code has 4 characters, at least 6 required"}}, preview.Issues)
+	assert.Contains(t, preview.Text, "Mã đơn: A123 [too short: 4/6]")
+}
+
+func TestRenderUndefinedLayoutParam(t *testing.T) {
+	tpl := client.ZnsTplDetailData{Layout: &client.ZnsTplLayout{Body: client.ZnsTplLayoutSection{Components: []client.ZnsTplComponent{
+		{Paragraph: &client.ZnsTplText{Value: "Mã OTP: <otp>"}},
+	}}}}
+
+	// A layout parameter the template does not list is undefined, even without value
+	preview, err := Render(tpl, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Issue{{Param: "otp", Kind: ISSUE_UNDEFINED, Message: "parameter otp is not defined by the template"}}, preview.Issues)
+	assert.Equal(t, "Mã OTP: [undefined: otp]", preview.Text)
+	assert.Contains(t, preview.HTML, `<mark class="zns-issue zns-undefined" title="parameter otp is not defined by the template">&lt;otp&gt;</mark>`)
+
+	data := map[string]string{"otp": "123456"}
+	preview, err = Render(tpl, data)
+	require.NoError(t, err)
+	assert.Equal(t, []Issue{{Param: "otp", Kind: ISSUE_UNDEFINED, Message: "parameter otp is not defined by the template"}}, preview.Issues)
+	assert.ErrorIs(t, tpl.ValidateTemplateData(data), &client.ZnsError{Code: client.TEMPLATE_DATA_TYPE_IS_NOT_DEFINED})
+	assert.Equal(t, "Mã OTP: 123456 [undefined]", preview.Text)
+}
+
+func TestRenderUndefinedLayoutAndDataParams(t *testing.T) {
+	tpl := client.ZnsTplDetailData{
+		ListParams: []client.ZnsTplDetailParam{{Name: "name", Type: client.ZNS_TPL_PARAM_TYPE_STRING, MaxLength: 30}},
+		Layout: &client.ZnsTplLayout{Body: client.ZnsTplLayoutSection{Components: []client.ZnsTplComponent{
+			{Paragraph: &client.ZnsTplText{Value: "Chào <name>, mã OTP: <otp>, hết hạn sau <ttl> phút"}},
+		}}},
+	}
+
+	// Undefined parameters are reported once by name, whether used by the layout, the data or both
+	preview, err := Render(tpl, map[string]string{"name": "Lan", "otp": "123456", "extra": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, []Issue{
+		{Param: "extra", Kind: ISSUE_UNDEFINED, Message: "parameter extra is not defined by the template"},
+		{Param: "otp", Kind: ISSUE_UNDEFINED, Message: "parameter otp is not defined by the template"},
+		{Param: "ttl", Kind: ISSUE_UNDEFINED, Message: "parameter ttl is not defined by the template"},
+	}, preview.Issues)
+	assert.Equal(t, "Chào Lan, mã OTP: 123456 [undefined], hết hạn sau [undefined: ttl] phút", preview.Text)
+}
+
+func TestRenderNoLayout(t *testing.T) {
+	_, err := Render(client.ZnsTplDetailData{TemplateID: 100}, nil)
+	assert.ErrorIs(t, err, ErrNoLayout)
+}
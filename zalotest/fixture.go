@@ -0,0 +1,304 @@
+package zalotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+	"github.com/ducminhgd/zalo-go-sdk/x/atomicfile"
+)
+
+// sensitiveHeaders are replaced by client.DEBUG_REDACTED in fixtures.
+var sensitiveHeaders = []string{"access_token", "secret_key", "appsecret_proof"}
+
+// sensitiveFields are JSON body fields, form fields and query parameters replaced by client.DEBUG_REDACTED in fixtures.
+var sensitiveFields = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"code":          true,
+	"code_verifier": true,
+	"secret_key":    true,
+	"phone":         true,
+}
+
+// unmatchedFields are JSON body fields ignored when matching a request with a recorded one:
+// the client generates a random tracking_id for each send without one.
+var unmatchedFields = []string{"tracking_id"}
+
+// Interaction is a sanitized HTTP request and response pair stored in a fixture file.
+type Interaction struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+type FixtureRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type FixtureResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type fixtureFile struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records sanitized interactions with the Zalo API.
+//
+// Install it with ZaloClient.UseHTTPClient(&http.Client{Transport: recorder}),
+// run the calls to capture, then call Save to write the fixture file:
+// access tokens, refresh tokens, authorization codes, secrets and phone numbers are scrubbed.
+type Recorder struct {
+	path      string
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder creates a recorder writing to the fixture file at path.
+// Requests are sent with transport, or http.DefaultTransport if it is nil.
+func NewRecorder(path string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{path: path, transport: transport}
+}
+
+// RoundTrip sends the request and records the sanitized interaction.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	respHeader := resp.Header.Clone()
+	respHeader.Del("Date")
+	interaction := Interaction{
+		Request: sanitizeRequest(req, reqBody),
+		Response: FixtureResponse{
+			StatusCode: resp.StatusCode,
+			Header:     respHeader,
+			Body:       string(sanitizeBody(respBody)),
+		},
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Interactions returns the interactions recorded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Save atomically writes the recorded interactions to the fixture file, creating its directory if needed.
+func (r *Recorder) Save() error {
+	data, err := json.MarshalIndent(fixtureFile{Interactions: r.Interactions()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// Replayer is an http.RoundTripper serving interactions from a fixture file without network.
+//
+// A request matches an interaction when its sanitized method, URL and body are equal to the
+// recorded ones; each interaction is served once, in recorded order. A request without a
+// matching interaction fails the test.
+type Replayer struct {
+	t testing.TB
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer loads the fixture file at path, failing the test if it cannot be read.
+func NewReplayer(t testing.TB, path string) *Replayer {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("zalotest: reading fixture: %v", err)
+	}
+	var file fixtureFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("zalotest: decoding fixture %s: %v", path, err)
+	}
+	return &Replayer{
+		t:            t,
+		interactions: file.Interactions,
+		used:         make([]bool, len(file.Interactions)),
+	}
+}
+
+// RoundTrip serves the first unused interaction matching the request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	want := sanitizeRequest(req, reqBody)
+	wantBody := matchBody(want.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		got := interaction.Request
+		if r.used[i] || got.Method != want.Method || got.URL != want.URL || matchBody(got.Body) != wantBody {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	err = fmt.Errorf("zalotest: no recorded interaction for %s %s %s", want.Method, want.URL, want.Body)
+	r.t.Error(err)
+	return nil, err
+}
+
+// Unused returns the interactions that have not been served yet.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// readBody reads a request or response body and replaces it with an in-memory copy.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+func sanitizeRequest(req *http.Request, body []byte) FixtureRequest {
+	header := req.Header.Clone()
+	for _, name := range sensitiveHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, client.DEBUG_REDACTED)
+		}
+	}
+
+	u := *req.URL
+	u.RawQuery = sanitizeValues(u.Query()).Encode()
+
+	return FixtureRequest{
+		Method: req.Method,
+		URL:    u.String(),
+		Header: header,
+		Body:   string(sanitizeBody(body)),
+	}
+}
+
+// sanitizeBody scrubs sensitive fields of a JSON or form-encoded body.
+// JSON bodies are re-encoded with sorted keys so that equal payloads compare equal.
+func sanitizeBody(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err == nil {
+		sanitized, err := json.Marshal(sanitizeJSON(value))
+		if err == nil {
+			return sanitized
+		}
+	}
+	if form, err := url.ParseQuery(string(body)); err == nil {
+		return []byte(sanitizeValues(form).Encode())
+	}
+	return body
+}
+
+func sanitizeJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if _, ok := field.(string); ok && sensitiveFields[key] {
+				v[key] = client.DEBUG_REDACTED
+			} else {
+				v[key] = sanitizeJSON(field)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = sanitizeJSON(v[i])
+		}
+	}
+	return value
+}
+
+// matchBody returns the sanitized body without the unmatchedFields of a JSON object.
+func matchBody(body string) string {
+	var value map[string]any
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return body
+	}
+	for _, key := range unmatchedFields {
+		delete(value, key)
+	}
+	matched, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return string(matched)
+}
+
+func sanitizeValues(values url.Values) url.Values {
+	for key := range values {
+		if sensitiveFields[key] {
+			values.Set(key, client.DEBUG_REDACTED)
+		}
+	}
+	return values
+}
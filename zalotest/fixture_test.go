@@ -0,0 +1,114 @@
+package zalotest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+// failRecorder captures test failures reported by a Replayer.
+type failRecorder struct {
+	testing.TB
+	mu     sync.Mutex
+	errors []string
+}
+
+func (f *failRecorder) Error(args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors = append(f.errors, fmt.Sprint(args...))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := NewServer()
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 9, TemplateName: "otp", Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+	path := filepath.Join(t.TempDir(), "testdata", "send.json")
+	ctx := context.Background()
+	request := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "9", TrackingID: "t-1"}
+
+	// Record against the fake server.
+	zc := srv.Client()
+	recorder := NewRecorder(path, srv.Server.Client().Transport)
+	zc.UseHTTPClient(&http.Client{Transport: recorder})
+
+	token, err := zc.RequestAccessToken(ctx, client.AccessTokenRequest{Code: "auth-code"})
+	require.NoError(t, err)
+	zc.SetAccessToken(token)
+	recorded, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	require.Equal(t, client.SUCCESS, recorded.Error)
+	require.NoError(t, recorder.Save())
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, secret := range []string{SECRET_KEY, CODE_VERIFIER, "auth-code", token.AccessToken, token.RefreshToken, "84987654321"} {
+		assert.NotContains(t, string(data), secret)
+	}
+
+	// Replay without the server.
+	replayer := NewReplayer(t, path)
+	zc = client.NewZaloClient(APP_ID, SECRET_KEY, CODE_VERIFIER)
+	zc.UseBaseURLs(srv.URL, srv.URL)
+	zc.UseHTTPClient(&http.Client{Transport: replayer})
+
+	replayedToken, err := zc.RequestAccessToken(ctx, client.AccessTokenRequest{Code: "another-code"})
+	require.NoError(t, err)
+	assert.Equal(t, client.DEBUG_REDACTED, replayedToken.AccessToken)
+	zc.SetAccessToken(replayedToken)
+
+	replayed, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, recorded.Data.MsgID, replayed.Data.MsgID)
+	assert.Empty(t, replayer.Unused())
+}
+
+func TestReplayGeneratedTrackingID(t *testing.T) {
+	srv := NewServer()
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 9, TemplateName: "otp", Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+	path := filepath.Join(t.TempDir(), "send.json")
+	ctx := context.Background()
+	// Without a TrackingID, each send has a new random one
+	request := client.ZnsSendMsgRequest{Phone: "84987654321", TemplateID: "9"}
+
+	zc := srv.Client()
+	recorder := NewRecorder(path, srv.Server.Client().Transport)
+	zc.UseHTTPClient(&http.Client{Transport: recorder})
+	recorded, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	require.NoError(t, recorder.Save())
+	srv.Close()
+
+	replayer := NewReplayer(t, path)
+	token := zc.GetAccessToken()
+	zc = client.NewZaloClient(APP_ID, SECRET_KEY, CODE_VERIFIER)
+	zc.UseBaseURLs(srv.URL, srv.URL)
+	zc.UseHTTPClient(&http.Client{Transport: replayer})
+	zc.SetAccessToken(token)
+	replayed, err := zc.SendZnsMessage(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, recorded.Data.MsgID, replayed.Data.MsgID)
+	assert.Empty(t, replayer.Unused())
+}
+
+func TestReplayerFailsOnUnmatchedRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"interactions":[]}`), 0o644))
+
+	failures := &failRecorder{TB: t}
+	zc := client.NewZaloClient(APP_ID, SECRET_KEY, CODE_VERIFIER)
+	zc.UseHTTPClient(&http.Client{Transport: NewReplayer(failures, path)})
+
+	_, err := zc.GetZnsTemplateDetail(context.Background(), "1")
+	assert.Error(t, err)
+	assert.Len(t, failures.errors, 1)
+}
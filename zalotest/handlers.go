@@ -0,0 +1,494 @@
+package zalotest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+// tplStatusNames maps the status filter of the template list endpoint to template statuses.
+var tplStatusNames = map[int]string{
+	client.ZNS_TPL_STATUS_ENABLED_CODE:        client.ZNS_TPL_STATUS_ENABLED_NAME,
+	client.ZNS_TPL_STATUS_PENDING_REVIEW_CODE: client.ZNS_TPL_STATUS_PENDING_REVIEW_NAME,
+	client.ZNS_TPL_STATUS_REJECTED_CODE:       client.ZNS_TPL_STATUS_REJECTED_NAME,
+	client.ZNS_TPL_STATUS_DISABLED_CODE:       client.ZNS_TPL_STATUS_DISABLED_NAME,
+}
+
+type errorBody struct {
+	Error   int    `json:"error"`
+	Message string `json:"message"`
+}
+
+type oauthErrorBody struct {
+	Error            int    `json:"error"`
+	ErrorName        string `json:"error_name"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type sendBody struct {
+	Phone        string            `json:"phone"`
+	TemplateID   string            `json:"template_id"`
+	TemplateData map[string]string `json:"template_data"`
+	TrackingID   string            `json:"tracking_id"`
+	Mode         client.SendMode   `json:"mode"`
+	JourneyToken string            `json:"journey_token"`
+}
+
+type statusData struct {
+	DeliveryTime string `json:"delivery_time"`
+	Message      string `json:"message"`
+	Status       int    `json:"status"`
+}
+
+type quotaData struct {
+	DailyQuota     string `json:"dailyQuota"`
+	RemainingQuota string `json:"remainingQuota"`
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	endpoint := endpointOf(r.URL.Path)
+
+	recorded := Request{
+		Endpoint: endpoint,
+		Method:   r.Method,
+		Header:   r.Header.Clone(),
+		Query:    flatten(r.URL.Query()),
+		Body:     body,
+	}
+	if endpoint == client.ENDPOINT_GET_ACCESS_TOKEN {
+		form, _ := url.ParseQuery(string(body))
+		recorded.Form = flatten(form)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, recorded)
+
+	if code, ok := s.popInjected(endpoint); ok {
+		if endpoint == client.ENDPOINT_GET_ACCESS_TOKEN {
+			writeJSON(w, oauthErrorBody{Error: code, ErrorName: "injected", ErrorDescription: fmt.Sprintf("injected error %d", code)})
+		} else {
+			writeJSON(w, errorBody{Error: code, Message: fmt.Sprintf("injected error %d", code)})
+		}
+		return
+	}
+
+	switch endpoint {
+	case client.ENDPOINT_GET_ACCESS_TOKEN:
+		s.handleAccessToken(w, r, recorded)
+		return
+	case "":
+		http.NotFound(w, r)
+		return
+	}
+
+	if !s.accessTokens[r.Header.Get("access_token")] {
+		writeJSON(w, errorBody{Error: client.ACCESS_TOKEN_INVALID, Message: "Access token is invalid"})
+		return
+	}
+	proof := r.Header.Get("appsecret_proof")
+	if (proof != "" || s.requireProof) && proof != client.GetAppSecretProof(r.Header.Get("access_token"), s.secretKey) {
+		writeJSON(w, errorBody{Error: client.APP_SECRET_PROOF_INVALID, Message: "App secret proof is invalid"})
+		return
+	}
+
+	switch endpoint {
+	case client.ENDPOINT_TEMPLATE_LIST:
+		s.handleTemplateList(w, recorded)
+	case client.ENDPOINT_TEMPLATE_DETAIL:
+		s.handleTemplateDetail(w, recorded)
+	case client.ENDPOINT_TEMPLATE_CREATE:
+		s.handleTemplateCreate(w, body)
+	case client.ENDPOINT_MESSAGE_SEND, client.ENDPOINT_MESSAGE_SEND_HASH_PHONE, client.ENDPOINT_MESSAGE_SEND_RSA:
+		s.handleSend(w, body, endpoint)
+	case client.ENDPOINT_RSA_KEY_GEN:
+		s.handleRsaKeyGen(w)
+	case client.ENDPOINT_RSA_KEY_GET:
+		s.handleRsaKeyGet(w)
+	case client.ENDPOINT_MESSAGE_INQUIRY_STATUS:
+		s.handleStatus(w, recorded)
+	case client.ENDPOINT_MESAGE_QUOTA:
+		writeJSON(w, struct {
+			errorBody
+			Data quotaData `json:"data"`
+		}{
+			errorBody: errorBody{Error: client.SUCCESS, Message: "Success"},
+			Data: quotaData{
+				DailyQuota:     strconv.Itoa(s.dailyQuota),
+				RemainingQuota: strconv.Itoa(s.remainingQuota),
+			},
+		})
+	default:
+		writeJSON(w, errorBody{Error: client.METHOD_UNSUPPORTED, Message: "Method is not supported by zalotest"})
+	}
+}
+
+// handleAccessToken issues tokens for the authorization_code and refresh_token grants.
+// Credential errors are reported with the ZNS application error codes,
+// and unknown refresh tokens with client.OAUTH_REFRESH_TOKEN_INVALID.
+func (s *Server) handleAccessToken(w http.ResponseWriter, r *http.Request, req Request) {
+	fail := func(code int, description string) {
+		writeJSON(w, oauthErrorBody{Error: code, ErrorName: "zalotest", ErrorDescription: description})
+	}
+
+	switch {
+	case req.Form["app_id"] != s.appID:
+		fail(client.APPLICATION_INVALID, "Invalid app_id")
+		return
+	case r.Header.Get("secret_key") != s.secretKey:
+		fail(client.APPLICATION_SECRET_KEY_INVALID, "Invalid secret key")
+		return
+	}
+
+	switch req.Form["grant_type"] {
+	case "authorization_code":
+		if req.Form["code"] == "" {
+			fail(client.INVALID_PARAMETER, "Missing authorization code")
+			return
+		}
+	case "refresh_token":
+		if !s.refreshTokens[req.Form["refresh_token"]] {
+			fail(client.OAUTH_REFRESH_TOKEN_INVALID, "Invalid refresh token")
+			return
+		}
+		delete(s.refreshTokens, req.Form["refresh_token"])
+	default:
+		fail(client.INVALID_PARAMETER, "Invalid grant_type")
+		return
+	}
+
+	token := s.issueToken()
+	writeJSON(w, map[string]string{
+		"access_token":  token.AccessToken,
+		"refresh_token": token.RefreshToken,
+		"expires_in":    strconv.Itoa(token.ExpiresIn),
+	})
+}
+
+func (s *Server) handleTemplateList(w http.ResponseWriter, req Request) {
+	offset, _ := strconv.Atoi(req.Query["offset"])
+	limit, _ := strconv.Atoi(req.Query["limit"])
+	status, _ := strconv.Atoi(req.Query["status"])
+
+	var records []client.ZnsTplListRecord
+	for _, tpl := range s.sortedTemplates() {
+		if status != client.ZNS_TPL_STATUS_UNKNOWN_CODE && tplStatusNames[status] != tpl.Status {
+			continue
+		}
+		records = append(records, client.ZnsTplListRecord{
+			TemplateID:      tpl.TemplateID,
+			TemplateName:    tpl.TemplateName,
+			Status:          tpl.Status,
+			TemplateQuality: tpl.TemplateQuality,
+		})
+	}
+
+	total := len(records)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	writeJSON(w, client.ZnsTplListResponse{
+		Error:    client.SUCCESS,
+		Message:  "Success",
+		Data:     records[offset:end],
+		Metadata: client.ZnsTplListMetadata{Total: total},
+	})
+}
+
+func (s *Server) handleTemplateDetail(w http.ResponseWriter, req Request) {
+	id, err := strconv.Atoi(req.Query["template_id"])
+	tpl, ok := s.templates[id]
+	if err != nil || !ok {
+		writeJSON(w, errorBody{Error: client.TEMPLATE_ID_INVALID, Message: "Template id is invalid"})
+		return
+	}
+	writeJSON(w, client.ZnsTplDetailResponse{Error: client.SUCCESS, Message: "Success", Data: tpl})
+}
+
+// createParamTypes maps the parameter types of created templates to the types of template details.
+var createParamTypes = map[client.ZnsTplParamType]string{
+	client.ZNS_TPL_CREATE_PARAM_QUANTITY: client.ZNS_TPL_PARAM_TYPE_NUMBER,
+	client.ZNS_TPL_CREATE_PARAM_TIME:     client.ZNS_TPL_PARAM_TYPE_DATE,
+	client.ZNS_TPL_CREATE_PARAM_CURRENCY: client.ZNS_TPL_PARAM_TYPE_CURRENCY,
+}
+
+// handleTemplateCreate checks a template with checkTemplateCreate and adds it, pending review.
+func (s *Server) handleTemplateCreate(w http.ResponseWriter, body []byte) {
+	var req client.ZnsTplCreateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSON(w, errorBody{Error: client.BODY_FORMAT_INVALID, Message: "Body format is invalid"})
+		return
+	}
+	if invalid := checkTemplateCreate(req); invalid != nil {
+		writeJSON(w, *invalid)
+		return
+	}
+
+	id := 1
+	for existing := range s.templates {
+		if existing >= id {
+			id = existing + 1
+		}
+	}
+	tpl := client.ZnsTplDetailData{
+		TemplateID:   id,
+		TemplateName: req.TemplateName,
+		Status:       client.ZNS_TPL_STATUS_PENDING_REVIEW_NAME,
+		TemplateTag:  string(req.Tag),
+		Layout:       &req.Layout,
+	}
+	for _, param := range req.Params {
+		paramType, ok := createParamTypes[param.Type]
+		if !ok {
+			paramType = client.ZNS_TPL_PARAM_TYPE_STRING
+		}
+		tpl.ListParams = append(tpl.ListParams, client.ZnsTplDetailParam{
+			Name:      param.Name,
+			Require:   true,
+			Type:      paramType,
+			MaxLength: param.Type.MaxLength(),
+		})
+	}
+	s.templateOrder = append(s.templateOrder, id)
+	s.templates[id] = tpl
+
+	writeJSON(w, client.ZnsTplCreateResponse{
+		Error:   client.SUCCESS,
+		Message: "Success",
+		Data: client.ZnsTplCreateData{
+			TemplateID:     strconv.Itoa(id),
+			TemplateName:   tpl.TemplateName,
+			TemplateType:   req.TemplateType,
+			TemplateStatus: tpl.Status,
+			Tag:            req.Tag,
+		},
+	})
+}
+
+// handleSend validates a send request against the stored template and quota and accepts the message.
+// The template data of E2EE templates must be sent encrypted to the RSA endpoint.
+func (s *Server) handleSend(w http.ResponseWriter, body []byte, endpoint string) {
+	fail := func(code int, message string) {
+		writeJSON(w, errorBody{Error: code, Message: message})
+	}
+	hashedPhone := endpoint == client.ENDPOINT_MESSAGE_SEND_HASH_PHONE
+	encrypted := endpoint == client.ENDPOINT_MESSAGE_SEND_RSA
+
+	var req sendBody
+	if err := json.Unmarshal(body, &req); err != nil {
+		fail(client.BODY_FORMAT_INVALID, "Body format is invalid")
+		return
+	}
+	if !hashedPhone && !validPhone(req.Phone) {
+		fail(client.PHONE_NUMBER_INVALID, "Phone number is invalid")
+		return
+	}
+
+	id, err := strconv.Atoi(req.TemplateID)
+	tpl, ok := s.templates[id]
+	if err != nil || !ok {
+		fail(client.TEMPLATE_ID_INVALID, "Template id is invalid")
+		return
+	}
+	if tpl.Status != client.ZNS_TPL_STATUS_ENABLED_NAME {
+		fail(client.ZNS_TEMPLATE_NOT_APPROVED, "ZNS template is not approved")
+		return
+	}
+
+	switch {
+	case encrypted && !tpl.E2EE:
+		fail(client.ZNS_NOT_AN_E2EE_TEMPLATE, "ZNS template is not an E2EE template")
+		return
+	case !encrypted && tpl.E2EE:
+		fail(client.INVALID_PARAMETER, "E2EE template data must be encrypted")
+		return
+	case encrypted && s.rsaKey == nil:
+		fail(client.RSA_KEY_NOT_EXISTED, "RSA key does not exist")
+		return
+	case encrypted:
+		data, err := decryptTemplateData(s.rsaKey, req.TemplateData)
+		if err != nil {
+			fail(client.RSA_MESSAGE_DECODE_FAILED, "Message cannot be decrypted")
+			return
+		}
+		req.TemplateData = data
+	}
+
+	if s.journeys[id] {
+		expiresAt, ok := s.journeyTokens[req.JourneyToken]
+		switch {
+		case req.JourneyToken == "":
+			fail(client.ZNS_JOURNEY_TOKEN_MISSING, "ZNS journey token is missing")
+			return
+		case !ok:
+			fail(client.ZNS_JOURNEY_TOKEN_INVALID, "ZNS journey token is invalid")
+			return
+		case !time.Now().Before(expiresAt):
+			fail(client.ZNS_JOURNEY_TOKEN_EXPIRED, "ZNS journey token is expired")
+			return
+		}
+	}
+
+	if invalid := checkTemplateData(tpl, req.TemplateData); invalid != nil {
+		fail(invalid.Error, invalid.Message)
+		return
+	}
+
+	switch req.Mode {
+	case client.SEND_MODE_DEFAULT:
+		if s.remainingQuota <= 0 {
+			fail(client.OUT_OF_QUOTA, "Out of quota")
+			return
+		}
+		s.remainingQuota--
+	case client.SEND_MODE_DEVELOPMENT:
+		if !s.admins[req.Phone] {
+			fail(client.TEST_MESSAGE_SENT_TO_ADMIN_ONLY, "Test message can only be sent to admins")
+			return
+		}
+		if s.devQuota <= 0 {
+			fail(client.OUT_OF_QUOTA_DEV, "Out of development quota")
+			return
+		}
+		s.devQuota--
+	default:
+		fail(client.INVALID_PARAMETER, "Mode is invalid")
+		return
+	}
+
+	msg := Message{
+		MsgID:        fmt.Sprintf("zalotest-msg-%d", len(s.messages)+1),
+		Phone:        req.Phone,
+		HashedPhone:  hashedPhone,
+		TemplateID:   req.TemplateID,
+		TemplateData: req.TemplateData,
+		TrackingID:   req.TrackingID,
+		Mode:         req.Mode,
+		JourneyToken: req.JourneyToken,
+		Encrypted:    encrypted,
+		SentTime:     time.Now().UTC().Truncate(time.Second),
+	}
+	s.messages = append(s.messages, msg)
+
+	var data client.ZnsSendMsgResponseData
+	data.MsgID = msg.MsgID
+	data.SentTime = msg.SentTime
+	data.SendingMode = client.SENDING_MODE_NORMAL
+	data.Quota.DailyQuota = strconv.Itoa(s.dailyQuota)
+	data.Quota.RemainingQuota = strconv.Itoa(s.remainingQuota)
+	writeJSON(w, client.ZnsSendMsgReponse{Error: client.SUCCESS, Message: "Success", Data: data})
+}
+
+func (s *Server) handleRsaKeyGen(w http.ResponseWriter) {
+	if s.rsaKey != nil {
+		writeJSON(w, errorBody{Error: client.RSA_KEY_EXISTED, Message: "RSA key already exists"})
+		return
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		writeJSON(w, errorBody{Error: client.RSA_KEY_CANNOT_BE_GENERATED, Message: err.Error()})
+		return
+	}
+	s.rsaKey = key
+	s.handleRsaKeyGet(w)
+}
+
+func (s *Server) handleRsaKeyGet(w http.ResponseWriter) {
+	if s.rsaKey == nil {
+		writeJSON(w, errorBody{Error: client.RSA_KEY_NOT_EXISTED, Message: "RSA key does not exist"})
+		return
+	}
+	der, err := x509.MarshalPKIXPublicKey(&s.rsaKey.PublicKey)
+	if err != nil {
+		writeJSON(w, errorBody{Error: client.UNKNOWN_ERROR, Message: err.Error()})
+		return
+	}
+	writeJSON(w, client.RsaKeyResponse{
+		Error:   client.SUCCESS,
+		Message: "Success",
+		Data:    client.RsaKeyData{Key: base64.StdEncoding.EncodeToString(der)},
+	})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, req Request) {
+	for _, msg := range s.messages {
+		if msg.MsgID != req.Query["message_id"] {
+			continue
+		}
+		if phone := req.Query["phone"]; phone != "" && phone != msg.Phone {
+			break
+		}
+		writeJSON(w, struct {
+			errorBody
+			Data statusData `json:"data"`
+		}{
+			errorBody: errorBody{Error: client.SUCCESS, Message: "Success"},
+			Data: statusData{
+				DeliveryTime: strconv.FormatInt(msg.SentTime.UnixMilli(), 10),
+				Message:      "The message has been delivered",
+				Status:       1,
+			},
+		})
+		return
+	}
+	writeJSON(w, errorBody{Error: client.MESSAGE_ID_INVALID, Message: "Message id is invalid"})
+}
+
+// validPhone reports whether phone is in the 84XXXXXXXXX form required by ZNS.
+func validPhone(phone string) bool {
+	if len(phone) < 11 || len(phone) > 12 || phone[:2] != "84" {
+		return false
+	}
+	for _, c := range phone {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// decryptTemplateData decrypts the values of template data encrypted with client.EncryptTemplateData.
+func decryptTemplateData(key *rsa.PrivateKey, data map[string]string) (map[string]string, error) {
+	decrypted := make(map[string]string, len(data))
+	for name, value := range data {
+		ciphertext, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := rsa.DecryptPKCS1v15(nil, key, ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		decrypted[name] = string(plaintext)
+	}
+	return decrypted, nil
+}
+
+func flatten(values url.Values) map[string]string {
+	flat := make(map[string]string, len(values))
+	for k := range values {
+		flat[k] = values.Get(k)
+	}
+	return flat
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,64 @@
+package zalotest
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+// The fake server checks requests with its own small rule set rather than the validators of the
+// client, so that a bug in the client validators is not hidden by the server agreeing with it.
+// The rules cover what tests rely on, not every check made by Zalo.
+
+// checkTemplateData checks the template data of a send request: every required parameter is set
+// and no value is longer than the maximum length of its parameter.
+func checkTemplateData(tpl client.ZnsTplDetailData, data map[string]string) *errorBody {
+	for _, param := range tpl.ListParams {
+		value := data[param.Name]
+		if value == "" {
+			if param.Require && !param.AcceptNull {
+				return &errorBody{Error: client.TEMPLATE_DATA_MISSING_PARAMETER_NAME, Message: fmt.Sprintf("Parameter %s is missing", param.Name)}
+			}
+			continue
+		}
+		if param.MaxLength > 0 && utf8.RuneCountInString(value) > param.MaxLength {
+			return &errorBody{Error: client.PARAMETER_NAME_DATA_BREAKS_LENGTH, Message: fmt.Sprintf("Parameter %s is longer than %d characters", param.Name, param.MaxLength)}
+		}
+	}
+	return nil
+}
+
+// checkTemplateCreate checks a template creation request: the template has a name and a known tag,
+// every parameter has a unique name and a known type, and every layout placeholder is a parameter.
+func checkTemplateCreate(req client.ZnsTplCreateRequest) *errorBody {
+	invalid := func(format string, args ...any) *errorBody {
+		return &errorBody{Error: client.INVALID_PARAMETER, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if req.TemplateName == "" {
+		return invalid("Template name is missing")
+	}
+	switch req.Tag {
+	case client.ZNS_TPL_TAG_TRANSACTION, client.ZNS_TPL_TAG_CUSTOMER_CARE, client.ZNS_TPL_TAG_PROMOTION:
+	default:
+		return invalid("Tag %q is invalid", req.Tag)
+	}
+
+	defined := make(map[string]bool, len(req.Params))
+	for _, param := range req.Params {
+		if defined[param.Name] {
+			return invalid("Parameter %s is defined twice", param.Name)
+		}
+		if param.Type.MaxLength() == 0 {
+			return invalid("Parameter %s has an invalid type", param.Name)
+		}
+		defined[param.Name] = true
+	}
+	for _, name := range req.Layout.Params() {
+		if !defined[name] {
+			return invalid("Parameter %s is not defined", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+// Package zalotest provides an in-process fake of the Zalo OAuth and ZNS APIs
+// for integration tests.
+//
+// A Server keeps templates, quota, issued tokens and sent messages in memory,
+// records every request it receives, and can be scripted to answer the next
+// request to an endpoint with any error code from the client package:
+//
+//	srv := zalotest.NewServer()
+//	defer srv.Close()
+//
+//	zc := srv.Client()
+//	srv.InjectError(client.ENDPOINT_MESSAGE_SEND, client.OUT_OF_QUOTA)
+//	resp, err := zc.SendZnsMessage(ctx, request) // resp.Error == client.OUT_OF_QUOTA
+package zalotest
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+const (
+	APP_ID        = "zalotest-app-id"
+	SECRET_KEY    = "zalotest-secret-key"
+	CODE_VERIFIER = "zalotest-code-verifier"
+
+	TOKEN_EXPIRES_IN = 90_000 // Lifetime of issued access tokens, in seconds
+)
+
+// Request is a request received by the fake server.
+type Request struct {
+	Endpoint string // The client ENDPOINT_* constant the request was sent to
+	Method   string
+	Header   http.Header
+	Query    map[string]string
+	Form     map[string]string // Form fields of OAuth requests
+	Body     []byte
+}
+
+// Message is a ZNS message accepted by the fake server.
+type Message struct {
+	MsgID        string
+	Phone        string // The phone number, or its SHA-256 hash for hash-phone sends
+	HashedPhone  bool
+	TemplateID   string
+	TemplateData map[string]string
+	TrackingID   string
+	Mode         client.SendMode
+	JourneyToken string
+	Encrypted    bool // Sent with encrypted template data to the RSA endpoint
+	SentTime     time.Time
+}
+
+// Server is a fake Zalo API server. All methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	appID          string
+	secretKey      string
+	requireProof   bool
+	templates      map[int]client.ZnsTplDetailData
+	templateOrder  []int
+	dailyQuota     int
+	remainingQuota int
+	devQuota       int
+	admins         map[string]bool
+	journeys       map[int]bool         // IDs of journey templates
+	journeyTokens  map[string]time.Time // Expiry of journey tokens
+	rsaKey         *rsa.PrivateKey
+	accessTokens   map[string]bool
+	refreshTokens  map[string]bool
+	tokenSeq       int
+	messages       []Message
+	injected       map[string][]int
+	requests       []Request
+}
+
+// NewServer starts a fake server accepting the APP_ID and SECRET_KEY credentials,
+// with no templates, a daily quota of 500 messages and a development quota of 100 messages.
+// The caller must call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		appID:          APP_ID,
+		secretKey:      SECRET_KEY,
+		templates:      make(map[int]client.ZnsTplDetailData),
+		dailyQuota:     500,
+		remainingQuota: 500,
+		devQuota:       100,
+		admins:         make(map[string]bool),
+		journeys:       make(map[int]bool),
+		journeyTokens:  make(map[string]time.Time),
+		accessTokens:   make(map[string]bool),
+		refreshTokens:  make(map[string]bool),
+		injected:       make(map[string][]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client for the fake server with the APP_ID, SECRET_KEY and
+// CODE_VERIFIER credentials, holding a freshly issued access token.
+func (s *Server) Client() *client.ZaloClient {
+	zc := client.NewZaloClient(APP_ID, SECRET_KEY, CODE_VERIFIER)
+	s.Configure(zc)
+	zc.SetAccessToken(s.IssueToken())
+	return zc
+}
+
+// Configure points an existing client to the fake server.
+func (s *Server) Configure(zc *client.ZaloClient) {
+	zc.UseBaseURLs(s.URL, s.URL)
+	zc.UseHTTPClient(s.Server.Client())
+}
+
+// RequireAppSecretProof makes authenticated endpoints reject requests without a valid
+// appsecret_proof header with client.APP_SECRET_PROOF_INVALID.
+// A proof that is sent is always checked.
+func (s *Server) RequireAppSecretProof(required bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requireProof = required
+}
+
+// IssueToken issues a new access token and refresh token accepted by the server.
+func (s *Server) IssueToken() client.AccessToken {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueToken()
+}
+
+// RevokeTokens invalidates every issued access token and refresh token.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessTokens = make(map[string]bool)
+	s.refreshTokens = make(map[string]bool)
+}
+
+// AddTemplate adds or replaces a template, keyed by its TemplateID.
+// Templates are listed in the order they are first added.
+func (s *Server) AddTemplate(template client.ZnsTplDetailData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.templates[template.TemplateID]; !ok {
+		s.templateOrder = append(s.templateOrder, template.TemplateID)
+	}
+	s.templates[template.TemplateID] = template
+}
+
+// RemoveTemplate removes a template.
+func (s *Server) RemoveTemplate(templateID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.templates, templateID)
+	for i, id := range s.templateOrder {
+		if id == templateID {
+			s.templateOrder = append(s.templateOrder[:i], s.templateOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+// SetQuota sets the daily quota and the remaining quota of the day.
+func (s *Server) SetQuota(daily, remaining int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dailyQuota = daily
+	s.remainingQuota = remaining
+}
+
+// Quota returns the daily quota and the remaining quota of the day.
+func (s *Server) Quota() (daily, remaining int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dailyQuota, s.remainingQuota
+}
+
+// AddAdmin adds the phone number of an admin of the OA, in the 84XXXXXXXXX form.
+// Messages sent in development mode are only accepted for admins.
+func (s *Server) AddAdmin(phone string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.admins[phone] = true
+}
+
+// SetDevQuota sets the remaining quota of messages sent in development mode.
+func (s *Server) SetDevQuota(remaining int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devQuota = remaining
+}
+
+// RequireJourney makes a template a journey template: its messages are only accepted with a
+// journey token issued by IssueJourneyToken.
+func (s *Server) RequireJourney(templateID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.journeys[templateID] = true
+}
+
+// IssueJourneyToken issues a journey token valid for ttl. A token issued with a negative ttl is expired.
+func (s *Server) IssueJourneyToken(ttl time.Duration) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := fmt.Sprintf("zalotest-journey-token-%d", len(s.journeyTokens)+1)
+	s.journeyTokens[token] = time.Now().Add(ttl)
+	return token
+}
+
+// InjectError makes the next request to an endpoint fail with an error code.
+//
+// endpoint is one of the client ENDPOINT_* constants and code is one of the
+// client error code constants, e.g. InjectError(client.ENDPOINT_MESSAGE_SEND, client.OUT_OF_QUOTA).
+// Errors injected several times for the same endpoint are returned in order, one per request.
+func (s *Server) InjectError(endpoint string, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injected[endpoint] = append(s.injected[endpoint], code)
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests received so far by an endpoint, in order.
+func (s *Server) RequestsTo(endpoint string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []Request
+	for _, r := range s.requests {
+		if r.Endpoint == endpoint {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// Messages returns the messages accepted so far, in order.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Reset clears recorded requests, accepted messages and pending injected errors.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.messages = nil
+	s.injected = make(map[string][]int)
+}
+
+// HashPhone returns the hash of a phone number as sent to the hash-phone endpoint.
+func HashPhone(phone string) string {
+	hash := sha256.Sum256([]byte(phone))
+	return hex.EncodeToString(hash[:])
+}
+
+// issueToken issues a new token pair. The caller holds mu.
+func (s *Server) issueToken() client.AccessToken {
+	s.tokenSeq++
+	token := client.AccessToken{
+		AccessToken:  fmt.Sprintf("zalotest-access-token-%d", s.tokenSeq),
+		RefreshToken: fmt.Sprintf("zalotest-refresh-token-%d", s.tokenSeq),
+		ExpiresIn:    TOKEN_EXPIRES_IN,
+	}
+	s.accessTokens[token.AccessToken] = true
+	s.refreshTokens[token.RefreshToken] = true
+	return token
+}
+
+// popInjected returns the next injected error code of an endpoint, if any. The caller holds mu.
+func (s *Server) popInjected(endpoint string) (int, bool) {
+	codes := s.injected[endpoint]
+	if len(codes) == 0 {
+		return 0, false
+	}
+	s.injected[endpoint] = codes[1:]
+	return codes[0], true
+}
+
+// sortedTemplates returns the templates in insertion order. The caller holds mu.
+func (s *Server) sortedTemplates() []client.ZnsTplDetailData {
+	templates := make([]client.ZnsTplDetailData, 0, len(s.templateOrder))
+	for _, id := range s.templateOrder {
+		templates = append(templates, s.templates[id])
+	}
+	return templates
+}
+
+// endpointOf maps a request path to its client ENDPOINT_* constant.
+func endpointOf(path string) string {
+	for _, endpoint := range []string{
+		client.ENDPOINT_MESSAGE_SEND,
+		client.ENDPOINT_MESSAGE_SEND_HASH_PHONE,
+		client.ENDPOINT_MESSAGE_SEND_RSA,
+		client.ENDPOINT_MESSAGE_INQUIRY_STATUS,
+		client.ENDPOINT_MESAGE_QUOTA,
+		client.ENDPOINT_RSA_KEY_GEN,
+		client.ENDPOINT_RSA_KEY_GET,
+		client.ENDPOINT_TEMPLATE_LIST,
+		client.ENDPOINT_TEMPLATE_DETAIL,
+		client.ENDPOINT_TEMPLATE_CREATE,
+		client.ENDPOINT_GET_ACCESS_TOKEN,
+	} {
+		base := client.BASE_URL_BUSINESS
+		if strings.HasPrefix(endpoint, client.BASE_URL_OAUTH) {
+			base = client.BASE_URL_OAUTH
+		}
+		if strings.TrimPrefix(endpoint, base) == path {
+			return endpoint
+		}
+	}
+	return ""
+}
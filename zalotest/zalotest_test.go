@@ -0,0 +1,123 @@
+package zalotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ducminhgd/zalo-go-sdk/client"
+)
+
+func call(t *testing.T, srv *Server, method, path, token string, body any) map[string]any {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&payload).Encode(body))
+	}
+	req, err := http.NewRequest(method, srv.URL+path, &payload)
+	require.NoError(t, err)
+	req.Header.Set("access_token", token)
+
+	resp, err := srv.Server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	return out
+}
+
+func TestServerHashPhoneStatusAndQuota(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 7, Status: client.ZNS_TPL_STATUS_ENABLED_NAME})
+	srv.SetQuota(10, 5)
+	token := srv.IssueToken().AccessToken
+
+	sent := call(t, srv, "POST", "/message/template/hashphone", token, map[string]any{
+		"phone":       HashPhone("84987654321"),
+		"template_id": "7",
+	})
+	require.EqualValues(t, client.SUCCESS, sent["error"])
+	msgID := sent["data"].(map[string]any)["msg_id"].(string)
+
+	messages := srv.Messages()
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].HashedPhone)
+
+	status := call(t, srv, "GET", "/message/status?message_id="+msgID, token, nil)
+	assert.EqualValues(t, client.SUCCESS, status["error"])
+
+	status = call(t, srv, "GET", "/message/status?message_id=unknown", token, nil)
+	assert.EqualValues(t, client.MESSAGE_ID_INVALID, status["error"])
+
+	quota := call(t, srv, "GET", "/message/quota", token, nil)
+	assert.Equal(t, map[string]any{"dailyQuota": "10", "remainingQuota": "4"}, quota["data"])
+
+	quota = call(t, srv, "GET", "/message/quota", "wrong-token", nil)
+	assert.EqualValues(t, client.ACCESS_TOKEN_INVALID, quota["error"])
+
+	assert.Len(t, srv.Requests(), 5)
+	assert.Len(t, srv.RequestsTo(client.ENDPOINT_MESAGE_QUOTA), 2)
+}
+
+func TestServerInjectErrorInOrder(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	token := srv.IssueToken().AccessToken
+
+	srv.InjectError(client.ENDPOINT_MESAGE_QUOTA, client.UNKNOWN_ERROR)
+	srv.InjectError(client.ENDPOINT_MESAGE_QUOTA, client.OA_ID_INVALID)
+
+	assert.EqualValues(t, client.UNKNOWN_ERROR, call(t, srv, "GET", "/message/quota", token, nil)["error"])
+	assert.EqualValues(t, client.OA_ID_INVALID, call(t, srv, "GET", "/message/quota", token, nil)["error"])
+	assert.EqualValues(t, client.SUCCESS, call(t, srv, "GET", "/message/quota", token, nil)["error"])
+}
+
+func TestServerChecksRequests(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddTemplate(client.ZnsTplDetailData{
+		TemplateID: 7,
+		Status:     client.ZNS_TPL_STATUS_ENABLED_NAME,
+		ListParams: []client.ZnsTplDetailParam{{Name: "otp", Require: true, Type: "STRING", MaxLength: 6}},
+	})
+	srv.AddTemplate(client.ZnsTplDetailData{TemplateID: 8, Status: client.ZNS_TPL_STATUS_DISABLED_NAME})
+	token := srv.IssueToken().AccessToken
+
+	send := func(templateID string, data map[string]string) any {
+		return call(t, srv, "POST", "/message/template", token, map[string]any{
+			"phone":         "84987654321",
+			"template_id":   templateID,
+			"template_data": data,
+		})["error"]
+	}
+	assert.EqualValues(t, client.TEMPLATE_DATA_MISSING_PARAMETER_NAME, send("7", nil))
+	assert.EqualValues(t, client.PARAMETER_NAME_DATA_BREAKS_LENGTH, send("7", map[string]string{"otp": "1234567"}))
+	assert.EqualValues(t, client.ZNS_TEMPLATE_NOT_APPROVED, send("8", nil))
+	assert.EqualValues(t, client.SUCCESS, send("7", map[string]string{"otp": "123456"}))
+
+	create := func(change func(r *client.ZnsTplCreateRequest)) any {
+		request := client.ZnsTplCreateRequest{
+			TemplateName: "Mã xác thực",
+			TemplateType: client.ZNS_TPL_TYPE_CUSTOM,
+			Tag:          client.ZNS_TPL_TAG_TRANSACTION,
+			Layout: client.ZnsTplLayout{Body: client.ZnsTplLayoutSection{Components: []client.ZnsTplComponent{
+				{Paragraph: &client.ZnsTplText{Value: "Mã của bạn là <otp>"}},
+			}}},
+			Params:     []client.ZnsTplCreateParam{{Name: "otp", Type: client.ZNS_TPL_CREATE_PARAM_OTP, SampleValue: "123456"}},
+			TrackingID: "tpl-1",
+		}
+		change(&request)
+		return call(t, srv, "POST", "/template/create", token, request)["error"]
+	}
+	assert.EqualValues(t, client.INVALID_PARAMETER, create(func(r *client.ZnsTplCreateRequest) { r.Tag = "9" }))
+	assert.EqualValues(t, client.INVALID_PARAMETER, create(func(r *client.ZnsTplCreateRequest) { r.Params = nil }))
+	assert.EqualValues(t, client.INVALID_PARAMETER, create(func(r *client.ZnsTplCreateRequest) { r.Params[0].Type = "99" }))
+	assert.EqualValues(t, client.SUCCESS, create(func(r *client.ZnsTplCreateRequest) {}))
+}